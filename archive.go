@@ -0,0 +1,138 @@
+package emaildomainstats
+
+import (
+	"archive/zip"
+	"bufio"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// wrappedReadCloser pairs a (possibly decompressed) io.Reader with the io.Closer(s) that must run
+// once the Processor is done reading from it, e.g. a gzip.Reader and the underlying file it reads
+// from both need to be closed.
+type wrappedReadCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (w *wrappedReadCloser) Close() error {
+	var err error
+	for _, c := range w.closers {
+		if cerr := c.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// WithGzip returns a ProcessorOption that wraps the already-configured reader in a gzip
+// decompressor. It must be used after WithCsvFileName or WithCsvReader.
+func WithGzip() ProcessorOption {
+	return func(p *Processor) error {
+		if p.fileReader == nil {
+			return fmt.Errorf("WithGzip must be used after WithCsvFileName or WithCsvReader")
+		}
+		return applyGzip(p)
+	}
+}
+
+// WithBzip2 returns a ProcessorOption that wraps the already-configured reader in a bzip2
+// decompressor. It must be used after WithCsvFileName or WithCsvReader.
+func WithBzip2() ProcessorOption {
+	return func(p *Processor) error {
+		if p.fileReader == nil {
+			return fmt.Errorf("WithBzip2 must be used after WithCsvFileName or WithCsvReader")
+		}
+		applyBzip2(p)
+		return nil
+	}
+}
+
+// WithAutoDetectCompression returns a ProcessorOption that peeks the first 4 bytes of the
+// already-configured reader and, based on their magic number, transparently wraps it in a gzip or
+// bzip2 decompressor. Plain CSV input is left untouched. It must be used after WithCsvFileName or
+// WithCsvReader.
+func WithAutoDetectCompression() ProcessorOption {
+	return func(p *Processor) error {
+		if p.fileReader == nil {
+			return fmt.Errorf("WithAutoDetectCompression must be used after WithCsvFileName or WithCsvReader")
+		}
+
+		br := bufio.NewReader(p.fileReader)
+		magic, err := br.Peek(4)
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("sniffing compression magic bytes: %w", err)
+		}
+
+		p.fileReader = &wrappedReadCloser{Reader: br, closers: []io.Closer{p.fileReader}}
+
+		switch {
+		case len(magic) >= 2 && magic[0] == 0x1f && magic[1] == 0x8b:
+			return applyGzip(p)
+		case len(magic) >= 3 && magic[0] == 'B' && magic[1] == 'Z' && magic[2] == 'h':
+			applyBzip2(p)
+			return nil
+		default:
+			return nil
+		}
+	}
+}
+
+// applyGzip wraps p.fileReader in a gzip.Reader, replacing it in place.
+func applyGzip(p *Processor) error {
+	gz, err := gzip.NewReader(p.fileReader)
+	if err != nil {
+		return fmt.Errorf("creating gzip reader: %w", err)
+	}
+	p.fileReader = &wrappedReadCloser{Reader: gz, closers: []io.Closer{gz, p.fileReader}}
+	return nil
+}
+
+// applyBzip2 wraps p.fileReader in a bzip2 decompressor, replacing it in place. bzip2.Reader has
+// no Close method of its own, so only the underlying reader needs closing.
+func applyBzip2(p *Processor) {
+	p.fileReader = &wrappedReadCloser{Reader: bzip2.NewReader(p.fileReader), closers: []io.Closer{p.fileReader}}
+}
+
+// WithZipArchive returns a ProcessorOption that configures the Processor to read every *.csv entry
+// in the named ZIP archive, processing them sequentially as if they were concatenated. It is a
+// standalone source option: unlike WithGzip/WithBzip2 it does not require (and cannot be combined
+// with) WithCsvFileName or WithCsvReader.
+func WithZipArchive(fileName string) ProcessorOption {
+	return func(p *Processor) error {
+		archive, err := zip.OpenReader(fileName)
+		if err != nil {
+			return fmt.Errorf("opening zip archive: %w", err)
+		}
+		p.archive = archive
+		return nil
+	}
+}
+
+// csvEntries returns the *.csv entries of p.archive opened for reading, in the order they appear
+// in the archive's central directory.
+func (p *Processor) csvEntries() ([]csvSource, error) {
+	var sources []csvSource
+	for _, f := range p.archive.File {
+		if !strings.EqualFold(path.Ext(f.Name), ".csv") {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			for _, opened := range sources {
+				opened.closer.Close()
+			}
+			return nil, fmt.Errorf("opening %s in zip archive: %w", f.Name, err)
+		}
+
+		rd := newCsvReader(rc)
+		sources = append(sources, csvSource{name: f.Name, reader: rd, closer: rc})
+	}
+
+	return sources, nil
+}
@@ -0,0 +1,191 @@
+package emaildomainstats_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	emaildomainstats "github.com/fcuenca/go-tech-test"
+)
+
+func TestProcessor_WithGzip(t *testing.T) {
+	content := "id,name,email\n1,Alice,alice@example.com\n2,Bob,bob@gmail.com\n"
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write([]byte(content)); err != nil {
+		t.Fatalf("compressing fixture: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("closing gzip writer: %v", err)
+	}
+
+	processor, err := emaildomainstats.New(
+		emaildomainstats.NewDomainStatTracker(),
+		emaildomainstats.WithCsvReader(io.NopCloser(&buf)),
+		emaildomainstats.WithGzip(),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+
+	result, err := processor.Process(context.Background())
+	if err != nil {
+		t.Fatalf("Process returned unexpected error: %v", err)
+	}
+
+	stats := result.Store.GetSorted()
+	if len(stats) != 2 {
+		t.Fatalf("expected 2 domains, got %d: %+v", len(stats), stats)
+	}
+}
+
+// bzip2-compressed "id,name,email\n1,Alice,alice@example.com\n2,Bob,bob@example.com\n", generated
+// with the bzip2 CLI since compress/bzip2 only implements decompression.
+const bzip2Fixture = "QlpoOTFBWSZTWdBIL6oAABRdgAAQAAUwAHAAPifAQCAAVDUDTRhGgEqnqepp6I08o/Uk0W5n" +
+	"LSxAz3Ib3Qh0eDKJN7UOZUaE/ZVJZ9DBxcfF3JFOFCQ0EgvqgA=="
+
+func TestProcessor_WithBzip2(t *testing.T) {
+	raw, err := base64.StdEncoding.DecodeString(bzip2Fixture)
+	if err != nil {
+		t.Fatalf("decoding fixture: %v", err)
+	}
+
+	processor, err := emaildomainstats.New(
+		emaildomainstats.NewDomainStatTracker(),
+		emaildomainstats.WithCsvReader(io.NopCloser(bytes.NewReader(raw))),
+		emaildomainstats.WithBzip2(),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+
+	result, err := processor.Process(context.Background())
+	if err != nil {
+		t.Fatalf("Process returned unexpected error: %v", err)
+	}
+
+	stats := result.Store.GetSorted()
+	if len(stats) != 1 || stats[0].Domain != "example.com" || stats[0].Count() != 2 {
+		t.Fatalf("expected example.com with count 2, got %+v", stats)
+	}
+}
+
+func TestProcessor_WithAutoDetectCompression(t *testing.T) {
+	content := "id,name,email\n1,Alice,alice@example.com\n"
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write([]byte(content)); err != nil {
+		t.Fatalf("compressing fixture: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("closing gzip writer: %v", err)
+	}
+
+	processor, err := emaildomainstats.New(
+		emaildomainstats.NewDomainStatTracker(),
+		emaildomainstats.WithCsvReader(io.NopCloser(&buf)),
+		emaildomainstats.WithAutoDetectCompression(),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+
+	result, err := processor.Process(context.Background())
+	if err != nil {
+		t.Fatalf("Process returned unexpected error: %v", err)
+	}
+
+	if stats := result.Store.GetSorted(); len(stats) != 1 {
+		t.Fatalf("expected 1 domain, got %d: %+v", len(stats), stats)
+	}
+}
+
+func TestProcessor_WithAutoDetectCompression_PlainCSV(t *testing.T) {
+	content := "id,name,email\n1,Alice,alice@example.com\n"
+
+	processor, err := emaildomainstats.New(
+		emaildomainstats.NewDomainStatTracker(),
+		emaildomainstats.WithCsvReader(io.NopCloser(bytes.NewReader([]byte(content)))),
+		emaildomainstats.WithAutoDetectCompression(),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+
+	result, err := processor.Process(context.Background())
+	if err != nil {
+		t.Fatalf("Process returned unexpected error: %v", err)
+	}
+
+	if stats := result.Store.GetSorted(); len(stats) != 1 {
+		t.Fatalf("expected 1 domain, got %d: %+v", len(stats), stats)
+	}
+}
+
+func TestProcessor_WithZipArchive(t *testing.T) {
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, "customers.zip")
+
+	archiveFile, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatalf("creating archive file: %v", err)
+	}
+
+	zw := zip.NewWriter(archiveFile)
+	entries := map[string]string{
+		"part1.csv":  "id,name,email\n1,Alice,alice@example.com\n",
+		"part2.csv":  "id,name,email\n2,Bob,bob@example.com\n",
+		"readme.txt": "not a csv file, should be skipped",
+	}
+	for name, content := range entries {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("creating entry %s: %v", name, err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("writing entry %s: %v", name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("closing zip writer: %v", err)
+	}
+	if err := archiveFile.Close(); err != nil {
+		t.Fatalf("closing archive file: %v", err)
+	}
+
+	processor, err := emaildomainstats.New(
+		emaildomainstats.NewDomainStatTracker(),
+		emaildomainstats.WithZipArchive(archivePath),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+
+	result, err := processor.Process(context.Background())
+	if err != nil {
+		t.Fatalf("Process returned unexpected error: %v", err)
+	}
+
+	stats := result.Store.GetSorted()
+	if len(stats) != 1 || stats[0].Domain != "example.com" || stats[0].Count() != 2 {
+		t.Fatalf("expected example.com with count 2 across both archive entries, got %+v", stats)
+	}
+}
+
+func TestProcessor_WithGzip_RequiresReader(t *testing.T) {
+	_, err := emaildomainstats.New(
+		emaildomainstats.NewDomainStatTracker(),
+		emaildomainstats.WithGzip(),
+	)
+	if err == nil {
+		t.Fatal("expected WithGzip to fail without a configured reader")
+	}
+}
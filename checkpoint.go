@@ -0,0 +1,110 @@
+package emaildomainstats
+
+import (
+	"container/heap"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// WithCheckpoint returns a ProcessorOption that persists a resumable checkpoint to path every
+// WithProgressInterval records. If path already exists when Process or ProcessStream starts, it
+// is loaded instead: already-processed data rows are skipped, and, for a *DomainStatTracker,
+// previously-seen domain counts are restored before processing continues. Resuming into a
+// SketchTracker or other custom StatTracker only restores the skip position, not counts, since
+// there is no general way to preload an arbitrary tracker.
+//
+// WithCheckpoint requires WithConcurrency(1) (the default). With concurrent workers, rows finish
+// out of order, so the saved position can race ahead of a still in-flight row; resuming would then
+// skip past it and permanently lose it. New returns an error if both are set.
+func WithCheckpoint(path string) ProcessorOption {
+	return func(p *Processor) error {
+		if path == "" {
+			return fmt.Errorf("checkpoint path must not be empty")
+		}
+		p.checkpointPath = path
+		return nil
+	}
+}
+
+// checkpointState is the on-disk representation written by WithCheckpoint. It does not reuse
+// DomainStat directly: DomainStat's count field is unexported (by design, since it is meant to be
+// read via Count()), which means encoding/json would silently drop it.
+type checkpointState struct {
+	LastLineNumber int64              `json:"lastLineNumber"`
+	Domains        []checkpointDomain `json:"domains"`
+}
+
+// checkpointDomain is the serializable form of a single DomainStat.
+type checkpointDomain struct {
+	Domain string `json:"domain"`
+	Count  int64  `json:"count"`
+}
+
+// loadResumeState loads the Processor's checkpoint, if configured, setting p.resumeSkip and
+// restoring domain counts into p.store where possible. It returns the record count to resume the
+// reported Status from (0 if no checkpoint is configured or none exists yet).
+func (p *Processor) loadResumeState() (int64, error) {
+	if p.checkpointPath == "" {
+		return 0, nil
+	}
+
+	state, err := loadCheckpoint(p.checkpointPath)
+	if err != nil {
+		return 0, err
+	}
+	if state == nil {
+		return 0, nil
+	}
+
+	p.resumeSkip = state.LastLineNumber
+	if dst, ok := p.store.(*DomainStatTracker); ok {
+		dst.restore(state.Domains)
+	}
+
+	return state.LastLineNumber, nil
+}
+
+// loadCheckpoint reads path, returning (nil, nil) if it does not exist.
+func loadCheckpoint(path string) (*checkpointState, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading checkpoint: %w", err)
+	}
+
+	var state checkpointState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("parsing checkpoint: %w", err)
+	}
+	return &state, nil
+}
+
+// save writes state to path via a temp file plus rename, so a crash mid-write can never corrupt
+// the previous, still-resumable checkpoint.
+func (state *checkpointState) save(path string) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("marshalling checkpoint: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("writing checkpoint: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// restore loads the given domain counts into the tracker, for checkpoint resume. It does not clear
+// domains already tracked, so it expects an empty tracker, and must only be called before any
+// concurrent Add calls begin.
+func (dst *DomainStatTracker) restore(stats []checkpointDomain) {
+	for _, stat := range stats {
+		entry := &domainEntry{Domain: stat.Domain}
+		entry.count.Store(stat.Count)
+		dst.cache.Store(stat.Domain, entry)
+		heap.Push(dst.heap, entry)
+	}
+}
@@ -0,0 +1,76 @@
+package emaildomainstats
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WithEmailColumn returns a ProcessorOption that resolves the email column by name instead of the
+// hardcoded index 2. The header row of each source is read, every header cell and name are passed
+// through the configured HeaderNormalizer (see WithHeaderNormalizer), and the resulting index is
+// used for every row. A header that does not contain name is a fatal error, reported once rather
+// than per row.
+func WithEmailColumn(name string) ProcessorOption {
+	return func(p *Processor) error {
+		if name == "" {
+			return fmt.Errorf("email column name must not be empty")
+		}
+		p.emailColumn = name
+		return nil
+	}
+}
+
+// WithHeaderNormalizer returns a ProcessorOption that overrides how header cells (and the name
+// passed to WithEmailColumn) are normalized before being compared. The default lower-cases and
+// trims surrounding whitespace.
+func WithHeaderNormalizer(normalize func(string) string) ProcessorOption {
+	return func(p *Processor) error {
+		if normalize == nil {
+			return fmt.Errorf("header normalizer must not be nil")
+		}
+		p.headerNormalizer = normalize
+		return nil
+	}
+}
+
+// WithEmailColumnIndex returns a ProcessorOption that selects the email column by a fixed,
+// zero-based index rather than by name. It is most useful for headerless files (see WithoutHeader)
+// but also works alongside a header row, which is simply skipped and ignored for column
+// resolution.
+func WithEmailColumnIndex(index int) ProcessorOption {
+	return func(p *Processor) error {
+		if index < 0 {
+			return fmt.Errorf("email column index must be at least 0")
+		}
+		p.emailColumnIndex = index
+		return nil
+	}
+}
+
+// WithoutHeader returns a ProcessorOption that configures the Processor to treat every row,
+// including the first, as data - use it for CSV input that has no header row. It is typically
+// combined with WithEmailColumnIndex, since there is no header to resolve WithEmailColumn against.
+func WithoutHeader() ProcessorOption {
+	return func(p *Processor) error {
+		p.skipHeader = false
+		return nil
+	}
+}
+
+// defaultHeaderNormalizer lower-cases and trims whitespace, so "Email Address", "email address "
+// and "EMAIL ADDRESS" are all treated as equivalent.
+func defaultHeaderNormalizer(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+// resolveEmailColumn returns the index of the header cell matching name once both are passed
+// through normalize, or an error if none match.
+func resolveEmailColumn(header []string, name string, normalize func(string) string) (int, error) {
+	target := normalize(name)
+	for i, col := range header {
+		if normalize(col) == target {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("email column %q not found in CSV header", name)
+}
@@ -0,0 +1,104 @@
+package emaildomainstats_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	emaildomainstats "github.com/fcuenca/go-tech-test"
+)
+
+func TestProcessor_WithEmailColumn(t *testing.T) {
+	content := "Customer ID,Email Address,Name\n" +
+		"1,alice@example.com,Alice\n" +
+		"2,bob@example.com,Bob\n"
+
+	processor, err := emaildomainstats.New(
+		emaildomainstats.NewDomainStatTracker(),
+		emaildomainstats.WithCsvReader(io.NopCloser(strings.NewReader(content))),
+		emaildomainstats.WithEmailColumn("email address"),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+
+	result, err := processor.Process(context.Background())
+	if err != nil {
+		t.Fatalf("Process returned unexpected error: %v", err)
+	}
+
+	stats := result.Store.GetSorted()
+	if len(stats) != 1 || stats[0].Domain != "example.com" || stats[0].Count() != 2 {
+		t.Fatalf("expected example.com with count 2, got %+v", stats)
+	}
+}
+
+func TestProcessor_WithEmailColumn_MissingColumnIsFatal(t *testing.T) {
+	content := "id,name\n1,Alice\n"
+
+	processor, err := emaildomainstats.New(
+		emaildomainstats.NewDomainStatTracker(),
+		emaildomainstats.WithCsvReader(io.NopCloser(strings.NewReader(content))),
+		emaildomainstats.WithEmailColumn("email"),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+
+	result, err := processor.Process(context.Background())
+	if err == nil {
+		t.Fatal("expected Process to return a fatal error for a missing email column")
+	}
+	if len(result.Errors) != 1 {
+		t.Fatalf("expected exactly 1 fatal error in Result.Errors, got %d: %v", len(result.Errors), result.Errors)
+	}
+}
+
+func TestProcessor_WithHeaderNormalizer(t *testing.T) {
+	content := "id,EMAIL,name\n1,alice@example.com,Alice\n"
+
+	processor, err := emaildomainstats.New(
+		emaildomainstats.NewDomainStatTracker(),
+		emaildomainstats.WithCsvReader(io.NopCloser(strings.NewReader(content))),
+		emaildomainstats.WithEmailColumn("Email"),
+		emaildomainstats.WithHeaderNormalizer(strings.ToUpper),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+
+	result, err := processor.Process(context.Background())
+	if err != nil {
+		t.Fatalf("Process returned unexpected error: %v", err)
+	}
+
+	if stats := result.Store.GetSorted(); len(stats) != 1 || stats[0].Domain != "example.com" {
+		t.Fatalf("expected example.com, got %+v", stats)
+	}
+}
+
+func TestProcessor_WithEmailColumnIndex_WithoutHeader(t *testing.T) {
+	content := "alice@example.com,1,Alice\n" +
+		"bob@example.com,2,Bob\n"
+
+	processor, err := emaildomainstats.New(
+		emaildomainstats.NewDomainStatTracker(),
+		emaildomainstats.WithCsvReader(io.NopCloser(strings.NewReader(content))),
+		emaildomainstats.WithEmailColumnIndex(0),
+		emaildomainstats.WithoutHeader(),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+
+	result, err := processor.Process(context.Background())
+	if err != nil {
+		t.Fatalf("Process returned unexpected error: %v", err)
+	}
+
+	stats := result.Store.GetSorted()
+	if len(stats) != 1 || stats[0].Domain != "example.com" || stats[0].Count() != 2 {
+		t.Fatalf("expected example.com with count 2, got %+v", stats)
+	}
+}
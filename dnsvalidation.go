@@ -0,0 +1,76 @@
+package emaildomainstats
+
+import (
+	"fmt"
+	"time"
+)
+
+// WithDomainValidation returns a ProcessorOption that rejects domains with no DNS presence,
+// confirmed via mode's lookup strategy, instead of accepting every syntactically valid email. It
+// is a convenience over WithDomainValidator for the common case of wanting real DNS validation
+// without hand-building an MXValidator; WithDNSTimeout, WithDNSCacheSize and
+// WithValidatorConcurrency tune the MXValidator it builds and may be passed in any order relative
+// to this option. It has no effect if WithDomainValidator has also been configured - an explicit
+// validator always wins, even NoopValidator passed to deliberately opt out. Failed lookups surface
+// as ordinary row-level errors, the same as any other DomainValidator rejection.
+func WithDomainValidation(mode DomainValidationMode) ProcessorOption {
+	return func(p *Processor) error {
+		if mode < NoValidation || mode > ALookup {
+			return fmt.Errorf("unknown domain validation mode %d", mode)
+		}
+		p.domainValidationMode = mode
+		return nil
+	}
+}
+
+// WithDNSTimeout returns a ProcessorOption that bounds each individual DNS lookup performed by
+// WithDomainValidation. The default is 5 seconds.
+func WithDNSTimeout(d time.Duration) ProcessorOption {
+	return func(p *Processor) error {
+		if d <= 0 {
+			return fmt.Errorf("DNS timeout must be positive")
+		}
+		p.dnsTimeout = d
+		return nil
+	}
+}
+
+// WithDNSCacheSize returns a ProcessorOption that bounds the number of domains WithDomainValidation
+// memoizes lookup outcomes for, evicting the least recently used entry once full. The default is
+// 10000 - comfortably larger than the distinct-domain count of most customer CSVs, even when row
+// counts run into the millions, since real-world lists skew heavily toward a handful of large
+// providers.
+func WithDNSCacheSize(n int) ProcessorOption {
+	return func(p *Processor) error {
+		if n < 1 {
+			return fmt.Errorf("DNS cache size must be at least 1")
+		}
+		p.dnsCacheSize = n
+		return nil
+	}
+}
+
+// WithValidatorConcurrency returns a ProcessorOption that bounds the number of DNS lookups
+// WithDomainValidation allows in flight at once, independent of WithConcurrency. This matters
+// because a DNS lookup is typically far slower than parsing a row, so validating with the same
+// concurrency as the rest of the pipeline can otherwise open far more simultaneous queries to the
+// resolver than intended. The default is unbounded (limited only by WithConcurrency).
+func WithValidatorConcurrency(n int) ProcessorOption {
+	return func(p *Processor) error {
+		if n < 1 {
+			return fmt.Errorf("validator concurrency must be at least 1")
+		}
+		p.validatorConcurrency = n
+		return nil
+	}
+}
+
+// newDomainValidationValidator builds the MXValidator backing WithDomainValidation, per the given
+// Processor configuration.
+func newDomainValidationValidator(mode DomainValidationMode, timeout time.Duration, cacheSize, concurrency int) *MXValidator {
+	opts := []MXValidatorOption{WithMXLookupMode(mode), WithMXTimeout(timeout), WithMXCacheSize(cacheSize)}
+	if concurrency > 0 {
+		opts = append(opts, WithMXConcurrency(concurrency))
+	}
+	return NewMXValidator(nil, 0, opts...)
+}
@@ -0,0 +1,114 @@
+package emaildomainstats_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	emaildomainstats "github.com/fcuenca/go-tech-test"
+)
+
+func TestWithDomainValidation_InvalidMode(t *testing.T) {
+	_, err := emaildomainstats.New(
+		emaildomainstats.NewDomainStatTracker(),
+		emaildomainstats.WithCsvReader(io.NopCloser(strings.NewReader(""))),
+		emaildomainstats.WithDomainValidation(emaildomainstats.DomainValidationMode(99)),
+	)
+	if err == nil {
+		t.Fatal("expected an error for an unknown DomainValidationMode")
+	}
+}
+
+func TestWithDNSTimeout_Invalid(t *testing.T) {
+	_, err := emaildomainstats.New(
+		emaildomainstats.NewDomainStatTracker(),
+		emaildomainstats.WithCsvReader(io.NopCloser(strings.NewReader(""))),
+		emaildomainstats.WithDNSTimeout(0),
+	)
+	if err == nil {
+		t.Fatal("expected an error for a non-positive DNS timeout")
+	}
+}
+
+func TestWithDNSCacheSize_Invalid(t *testing.T) {
+	_, err := emaildomainstats.New(
+		emaildomainstats.NewDomainStatTracker(),
+		emaildomainstats.WithCsvReader(io.NopCloser(strings.NewReader(""))),
+		emaildomainstats.WithDNSCacheSize(0),
+	)
+	if err == nil {
+		t.Fatal("expected an error for a DNS cache size below 1")
+	}
+}
+
+func TestWithValidatorConcurrency_Invalid(t *testing.T) {
+	_, err := emaildomainstats.New(
+		emaildomainstats.NewDomainStatTracker(),
+		emaildomainstats.WithCsvReader(io.NopCloser(strings.NewReader(""))),
+		emaildomainstats.WithValidatorConcurrency(0),
+	)
+	if err == nil {
+		t.Fatal("expected an error for a validator concurrency below 1")
+	}
+}
+
+// TestWithDomainValidation_DoesNotOverrideExplicitValidator confirms that an explicit
+// WithDomainValidator always wins over WithDomainValidation, so this case never needs a live DNS
+// resolver to exercise (WithDomainValidation builds on the already-untested MXValidator and is
+// likewise left unverified here for the same reason: it requires a live resolver).
+func TestWithDomainValidation_DoesNotOverrideExplicitValidator(t *testing.T) {
+	content := "id,name,email\n1,Alice,alice@example.com\n2,Bob,bob@example.net\n"
+
+	processor, err := emaildomainstats.New(
+		emaildomainstats.NewDomainStatTracker(),
+		emaildomainstats.WithCsvReader(io.NopCloser(strings.NewReader(content))),
+		emaildomainstats.WithDomainValidator(emaildomainstats.StaticAllowlistValidator(map[string]bool{
+			"example.com": true,
+		})),
+		emaildomainstats.WithDomainValidation(emaildomainstats.MXLookup),
+		emaildomainstats.WithDNSTimeout(time.Second),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+
+	result, err := processor.Process(context.Background())
+	if err != nil {
+		t.Fatalf("Process returned unexpected error: %v", err)
+	}
+
+	stats := result.Store.GetSorted()
+	if len(stats) != 1 || stats[0].Domain != "example.com" {
+		t.Fatalf("expected only the allowlisted example.com to survive, got %+v", stats)
+	}
+}
+
+// TestWithDomainValidation_DoesNotOverrideExplicitNoopOptOut confirms that explicitly passing
+// WithDomainValidator(NoopValidator{}) to opt out of validation also wins over WithDomainValidation
+// - unlike an untouched default, which WithDomainValidation is free to replace.
+func TestWithDomainValidation_DoesNotOverrideExplicitNoopOptOut(t *testing.T) {
+	content := "id,name,email\n1,Alice,alice@example.com\n2,Bob,bob@nonexistent-domain.invalid\n"
+
+	processor, err := emaildomainstats.New(
+		emaildomainstats.NewDomainStatTracker(),
+		emaildomainstats.WithCsvReader(io.NopCloser(strings.NewReader(content))),
+		emaildomainstats.WithDomainValidator(emaildomainstats.NoopValidator{}),
+		emaildomainstats.WithDomainValidation(emaildomainstats.MXLookup),
+		emaildomainstats.WithDNSTimeout(time.Second),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+
+	result, err := processor.Process(context.Background())
+	if err != nil {
+		t.Fatalf("Process returned unexpected error: %v", err)
+	}
+
+	stats := result.Store.GetSorted()
+	if len(stats) != 2 {
+		t.Fatalf("expected both domains to survive since the explicit NoopValidator opt-out must not be overridden, got %+v", stats)
+	}
+}
@@ -7,33 +7,59 @@
 //
 // Write this package as you normally would for any production grade code that would be deployed to a live system.
 //
-// Please stick to using the standard library.
+// Dependencies are now tracked via go.mod: golang.org/x/sync for structured worker orchestration
+// and github.com/prometheus/client_golang for metrics, plus github.com/prometheus/client_model
+// used by the metrics tests, are the only ones in use. Prefer the standard library otherwise.
 
 package emaildomainstats
 
 import (
+	"archive/zip"
 	"context"
-	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
 	"net/mail"
 	"os"
 	"strings"
 	"sync"
+	"time"
 )
 
 // Result represents the outcome of processing email domain statistics.
 // contains a pointer to the DomainStatTracker and any errors encountered during processing.
 type Result struct {
-	Store  *DomainStatTracker
+	Store  StatTracker
 	Errors []error
 }
 
 // Processor handles the processing of email domain statistics from a CSV file or any reader.
 type Processor struct {
-	store       *DomainStatTracker
+	store       StatTracker
 	fileReader  io.ReadCloser
-	concurrency int
+	archive     *zip.ReadCloser
+	concurrency  int
+	errorBudget  int
+	validator    DomainValidator
+	validatorSet bool
+	metrics      *processorMetrics
+
+	domainValidationMode DomainValidationMode
+	dnsTimeout           time.Duration
+	dnsCacheSize         int
+	validatorConcurrency int
+
+	emailColumn      string
+	emailColumnIndex int
+	headerNormalizer func(string) string
+	skipHeader       bool
+
+	errorHandler ErrorHandler
+	quarantine   *quarantineWriter
+
+	status         *processorStatus
+	checkpointPath string
+	resumeSkip     int64
 }
 
 // ProcessorOption is a function type used to configure a Processor.
@@ -61,7 +87,8 @@ func WithCsvReader(reader io.ReadCloser) ProcessorOption {
 }
 
 // WithConcurrency returns a ProcessorOption that sets the concurrency level for processing.
-// concurrency must be at least 1.
+// concurrency must be at least 1. A concurrency greater than 1 cannot be combined with
+// WithCheckpoint: see WithCheckpoint for why.
 func WithConcurrency(concurrency int) ProcessorOption {
 	return func(p *Processor) error {
 		if concurrency < 1 {
@@ -72,12 +99,46 @@ func WithConcurrency(concurrency int) ProcessorOption {
 	}
 }
 
-// New creates a new Processor with the given DomainStatTracker and options.
+// WithErrorBudget returns a ProcessorOption that aborts processing once more than n row-level
+// errors have accumulated. A budget of 0 (the default) means unlimited.
+func WithErrorBudget(n int) ProcessorOption {
+	return func(p *Processor) error {
+		if n < 0 {
+			return fmt.Errorf("error budget must be at least 0")
+		}
+		p.errorBudget = n
+		return nil
+	}
+}
+
+// WithDomainValidator returns a ProcessorOption that runs every extracted domain through v before
+// it is added to the DomainStatTracker. Domains that fail validation are dropped and reported as
+// a row-level error in Result.Errors. It always wins over WithDomainValidation, even when v is
+// NoopValidator{} passed to deliberately opt out of validation.
+func WithDomainValidator(v DomainValidator) ProcessorOption {
+	return func(p *Processor) error {
+		if v == nil {
+			return fmt.Errorf("domain validator must not be nil")
+		}
+		p.validator = v
+		p.validatorSet = true
+		return nil
+	}
+}
+
+// New creates a new Processor with the given StatTracker and options.
 // returns an error if the configuration is invalid.
-func New(store *DomainStatTracker, opts ...ProcessorOption) (*Processor, error) {
+func New(store StatTracker, opts ...ProcessorOption) (*Processor, error) {
 	p := &Processor{
-		store:       store,
-		concurrency: 1,
+		store:            store,
+		concurrency:      1,
+		validator:        NoopValidator{},
+		emailColumnIndex: -1,
+		headerNormalizer: defaultHeaderNormalizer,
+		skipHeader:       true,
+		status:           newProcessorStatus(),
+		dnsTimeout:       5 * time.Second,
+		dnsCacheSize:     10000,
 	}
 
 	for _, opt := range opts {
@@ -86,141 +147,75 @@ func New(store *DomainStatTracker, opts ...ProcessorOption) (*Processor, error)
 		}
 	}
 
-	if p.fileReader == nil {
+	if p.fileReader == nil && p.archive == nil {
 		return nil, fmt.Errorf("no file or reader specified")
 	}
 
-	return p, nil
-}
-
-// Process starts processing the CSV data and returns a Result.
-// uses the configured concurrency to process the data in parallel.
-func (p *Processor) Process(ctx context.Context) Result {
-	result := Result{
-		Store:  p.store,
-		Errors: []error{},
+	if p.checkpointPath != "" && p.concurrency > 1 {
+		return nil, fmt.Errorf("WithCheckpoint requires WithConcurrency(1): concurrent workers commit rows out of order, so a checkpoint cannot record a resume position that is guaranteed to exclude a still in-flight row")
 	}
 
-	defer p.fileReader.Close()
-
-	emailCh := make(chan string)
-	errorCh := make(chan error)
-	var wg sync.WaitGroup
-
-	rd := csv.NewReader(p.fileReader)
-	rd.FieldsPerRecord = -1
-	rd.LazyQuotes = true
-
-	// Skip header
-	if _, err := rd.Read(); err != nil {
-		result.Errors = append(result.Errors, fmt.Errorf("error reading CSV header: %w", err))
-		return result
+	if p.domainValidationMode != NoValidation && !p.validatorSet {
+		p.validator = newDomainValidationValidator(p.domainValidationMode, p.dnsTimeout, p.dnsCacheSize, p.validatorConcurrency)
 	}
 
-	wg.Add(1)
-	go p.reader(ctx, rd, emailCh, errorCh, &wg)
+	return p, nil
+}
 
-	for i := 0; i < p.concurrency; i++ {
-		wg.Add(1)
-		go p.worker(ctx, emailCh, errorCh, &wg)
+// closeSource closes whichever of fileReader/archive is configured.
+func (p *Processor) closeSource() {
+	if p.fileReader != nil {
+		p.fileReader.Close()
 	}
+	if p.archive != nil {
+		p.archive.Close()
+	}
+}
 
-	done := make(chan struct{})
-	go func() {
-		wg.Wait()
-		close(done)
-	}()
-
-	for {
-		select {
-
-		case <-ctx.Done():
-			<-done
-			close(errorCh)
+// Process starts processing the CSV data and returns a Result. It is a thin wrapper around
+// ProcessStream that accumulates every row-level error into Result.Errors instead of requiring a
+// callback. The returned error is non-nil only when processing was aborted (reader failure, error
+// budget exceeded, or context cancellation) as opposed to merely completing with row-level
+// errors.
+func (p *Processor) Process(ctx context.Context) (Result, error) {
+	result := Result{
+		Store:  p.store,
+		Errors: []error{},
+	}
 
-			for err := range errorCh {
-				result.Errors = append(result.Errors, err)
-			}
+	var mu sync.Mutex
+	var rowErrors int
+	var budgetErr error
 
-			result.Errors = append(result.Errors, fmt.Errorf("process was cancelled: %w", ctx.Err()))
-			return result
+	err := p.ProcessStream(ctx, StreamOptions{
+		OnError: func(err error) bool {
+			mu.Lock()
+			defer mu.Unlock()
 
-		case err, ok := <-errorCh:
-			if !ok {
-				return result
-			}
 			result.Errors = append(result.Errors, err)
-
-		case <-done:
-			close(errorCh)
-
-			for err := range errorCh {
-				result.Errors = append(result.Errors, err)
+			rowErrors++
+			if p.errorBudget > 0 && rowErrors > p.errorBudget && budgetErr == nil {
+				budgetErr = fmt.Errorf("row error budget of %d exceeded", p.errorBudget)
+				return false
 			}
+			return true
+		},
+	})
 
-			return result
-
-		}
+	if budgetErr != nil {
+		return result, budgetErr
 	}
-}
-
-// reader reads records from the CSV buffer and sends them to the emailCh channel.
-// reports any errors encountered during reading to the errorCh channel.
-func (p *Processor) reader(ctx context.Context, buffer *csv.Reader, emailCh chan<- string, errorCh chan<- error, wg *sync.WaitGroup) {
-	defer wg.Done()
-	defer close(emailCh)
-
-	lineNumber := 1
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		default:
-			record, err := buffer.Read()
-			if err == io.EOF {
-				return
-			}
-
-			if err != nil {
-				errorCh <- fmt.Errorf("error reading CSV at line %d: %w", lineNumber, err)
-				lineNumber++
-				continue
-			}
 
-			if len(record) <= 2 || record[2] == "" {
-				errorCh <- fmt.Errorf("missing or empty email at line %d", lineNumber)
-				lineNumber++
-				continue
-			}
-
-			emailCh <- record[2]
-			lineNumber++
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			result.Errors = append(result.Errors, fmt.Errorf("process was cancelled: %w", err))
+		} else {
+			result.Errors = append(result.Errors, fmt.Errorf("process aborted: %w", err))
 		}
+		return result, err
 	}
-}
 
-// worker reads from emailCh and processes emails
-func (p *Processor) worker(ctx context.Context, emailCh <-chan string, errorCh chan<- error, wg *sync.WaitGroup) {
-	defer wg.Done()
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case email, ok := <-emailCh:
-			if !ok {
-				return
-			}
-
-			domain, err := extractDomain(strings.ToLower(email))
-			if err != nil {
-				errorCh <- fmt.Errorf("extracting domain: (%s): %w", email, err)
-				continue
-			}
-
-			p.store.Add(domain)
-		}
-	}
+	return result, nil
 }
 
 // extractDomain is an internal function for extracting the domain
@@ -43,9 +43,12 @@ func BenchmarkProcessFile(b *testing.B) {
 				ctx := context.Background()
 				b.StartTimer()
 
-				result := processor.Process(ctx)
+				result, err := processor.Process(ctx)
 
 				b.StopTimer()
+				if err != nil {
+					b.Fatalf("ProcessFile returned error: %v", err)
+				}
 				if len(result.Errors) > 0 {
 					b.Fatalf("ProcessFile encountered errors: %v", result.Errors)
 				}
@@ -168,7 +171,10 @@ func TestProcessor_EmailHandling(t *testing.T) {
 				t.Fatalf("Failed to create processor: %v", err)
 			}
 
-			result := processor.Process(context.Background())
+			result, err := processor.Process(context.Background())
+			if err != nil {
+				t.Fatalf("Process returned unexpected fatal error: %v", err)
+			}
 
 			if tt.wantErr {
 				if len(result.Errors) == 0 {
@@ -280,7 +286,10 @@ func TestProcessor_EmailDomainStats(t *testing.T) {
 				t.Fatalf("Failed to create processor: %v", err)
 			}
 
-			result := processor.Process(context.Background())
+			result, err := processor.Process(context.Background())
+			if err != nil {
+				t.Fatalf("Process returned unexpected fatal error: %v", err)
+			}
 
 			if len(result.Errors) > 0 {
 				t.Errorf("Unexpected errors: %v", result.Errors)
@@ -392,7 +401,10 @@ func TestProcessor_CSVProcessing(t *testing.T) {
 				t.Fatalf("Failed to create processor: %v", err)
 			}
 
-			result := processor.Process(context.Background())
+			result, err := processor.Process(context.Background())
+			if err != nil {
+				t.Fatalf("Process returned unexpected fatal error: %v", err)
+			}
 
 			if len(result.Errors) != len(tt.expectedErrors) {
 				t.Errorf("Expected %d errors, got %d", len(tt.expectedErrors), len(result.Errors))
@@ -496,7 +508,7 @@ func TestProcessor_ContextCancellation(t *testing.T) {
 				cancel()
 			}()
 
-			result := processor.Process(ctx)
+			result, _ := processor.Process(ctx)
 
 			if !tt.expectedResult(result) {
 				t.Errorf("Unexpected result: %+v", result)
@@ -504,3 +516,28 @@ func TestProcessor_ContextCancellation(t *testing.T) {
 		})
 	}
 }
+
+func TestProcessor_WithErrorBudget(t *testing.T) {
+	content := "id,name,email\n" + strings.Repeat("1,Bad,not-an-email\n", 5)
+
+	processor, err := emaildomainstats.New(
+		emaildomainstats.NewDomainStatTracker(),
+		emaildomainstats.WithCsvReader(io.NopCloser(strings.NewReader(content))),
+		emaildomainstats.WithConcurrency(1),
+		emaildomainstats.WithErrorBudget(2),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+
+	result, err := processor.Process(context.Background())
+	if err == nil {
+		t.Fatal("expected Process to abort once the error budget was exceeded")
+	}
+	if !strings.Contains(err.Error(), "row error budget of 2 exceeded") {
+		t.Fatalf("expected a row error budget error, got: %v", err)
+	}
+	if len(result.Errors) <= 2 {
+		t.Fatalf("expected more than 2 accumulated errors, got %d", len(result.Errors))
+	}
+}
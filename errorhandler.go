@@ -0,0 +1,65 @@
+package emaildomainstats
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ErrorHandler is invoked for every non-fatal row-level error encountered while processing (CSV
+// parse errors, missing/empty email fields, domain extraction failures, ...). lineNumber is the
+// 1-based data row number within its source, not counting the header row (0 for an error reading
+// the header itself); raw holds the row's fields when available, and is nil for
+// errors discovered before a row could be split into fields. Returning stop aborts processing,
+// the same as a fatal reader error would, after letting in-flight work drain.
+type ErrorHandler func(lineNumber int, raw []string, err error) (stop bool)
+
+// WithErrorHandler returns a ProcessorOption that additionally routes every row-level error
+// through h, alongside the existing Result.Errors/OnError reporting. It is typically used to
+// bound memory on very large files (stop once enough errors have been seen) or to forward errors
+// somewhere other than Result.Errors; use WithQuarantineWriter if all you need is to save off the
+// rejected rows themselves.
+func WithErrorHandler(h ErrorHandler) ProcessorOption {
+	return func(p *Processor) error {
+		if h == nil {
+			return fmt.Errorf("error handler must not be nil")
+		}
+		p.errorHandler = h
+		return nil
+	}
+}
+
+// WithQuarantineWriter returns a ProcessorOption that writes every rejected row, as CSV, to w so
+// operators can re-process them later. It composes with WithErrorHandler rather than replacing
+// it - both run for every rejected row.
+func WithQuarantineWriter(w io.Writer) ProcessorOption {
+	return func(p *Processor) error {
+		if w == nil {
+			return fmt.Errorf("quarantine writer must not be nil")
+		}
+		p.quarantine = &quarantineWriter{w: csv.NewWriter(w)}
+		return nil
+	}
+}
+
+// quarantineWriter guards a csv.Writer with a mutex so the reader goroutine and concurrent worker
+// goroutines can all safely write rejected rows to it.
+type quarantineWriter struct {
+	mu sync.Mutex
+	w  *csv.Writer
+}
+
+// write appends raw as a CSV row, flushing immediately so partial output survives a crash. Rows
+// with no raw fields available (e.g. an unparsable CSV line) are silently skipped.
+func (q *quarantineWriter) write(raw []string) {
+	if raw == nil {
+		return
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.w.Write(raw)
+	q.w.Flush()
+}
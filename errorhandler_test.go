@@ -0,0 +1,103 @@
+package emaildomainstats_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"io"
+	"strings"
+	"testing"
+
+	emaildomainstats "github.com/fcuenca/go-tech-test"
+)
+
+func TestProcessor_WithErrorHandler_StopsProcessing(t *testing.T) {
+	content := "id,name,email\n" +
+		"1,Alice,alice@example.com\n" +
+		"2,Bob,bobgmail.com\n" +
+		"3,Charlie,charlie@example.com\n"
+
+	var handled int
+	processor, err := emaildomainstats.New(
+		emaildomainstats.NewDomainStatTracker(),
+		emaildomainstats.WithCsvReader(io.NopCloser(strings.NewReader(content))),
+		emaildomainstats.WithConcurrency(1),
+		emaildomainstats.WithErrorHandler(func(lineNumber int, raw []string, err error) bool {
+			handled++
+			return true
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+
+	_, err = processor.Process(context.Background())
+	if err == nil {
+		t.Fatal("expected Process to return an error when the handler asks to stop")
+	}
+	if handled != 1 {
+		t.Errorf("expected handler to run exactly once before stopping, got %d", handled)
+	}
+}
+
+func TestProcessor_WithErrorHandler_ContinuesAndStillPopulatesResultErrors(t *testing.T) {
+	content := "id,name,email\n" +
+		"1,Alice,alice@example.com\n" +
+		"2,Bob,bobgmail.com\n" +
+		"3,Charlie,charlie@example.com\n"
+
+	var lineNumbers []int
+	processor, err := emaildomainstats.New(
+		emaildomainstats.NewDomainStatTracker(),
+		emaildomainstats.WithCsvReader(io.NopCloser(strings.NewReader(content))),
+		emaildomainstats.WithConcurrency(1),
+		emaildomainstats.WithErrorHandler(func(lineNumber int, raw []string, err error) bool {
+			lineNumbers = append(lineNumbers, lineNumber)
+			return false
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+
+	result, err := processor.Process(context.Background())
+	if err != nil {
+		t.Fatalf("Process returned unexpected error: %v", err)
+	}
+
+	if len(lineNumbers) != 1 || lineNumbers[0] != 2 {
+		t.Errorf("expected the handler to see line 2, got %v", lineNumbers)
+	}
+	if len(result.Errors) != 1 {
+		t.Errorf("expected Result.Errors to still be populated, got %v", result.Errors)
+	}
+}
+
+func TestProcessor_WithQuarantineWriter(t *testing.T) {
+	content := "id,name,email\n" +
+		"1,Alice,alice@example.com\n" +
+		"2,Bob,bobgmail.com\n"
+
+	var quarantined bytes.Buffer
+	processor, err := emaildomainstats.New(
+		emaildomainstats.NewDomainStatTracker(),
+		emaildomainstats.WithCsvReader(io.NopCloser(strings.NewReader(content))),
+		emaildomainstats.WithConcurrency(1),
+		emaildomainstats.WithQuarantineWriter(&quarantined),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+
+	if _, err := processor.Process(context.Background()); err != nil {
+		t.Fatalf("Process returned unexpected error: %v", err)
+	}
+
+	rows, err := csv.NewReader(&quarantined).ReadAll()
+	if err != nil {
+		t.Fatalf("reading quarantine output: %v", err)
+	}
+	if len(rows) != 1 || rows[0][1] != "Bob" {
+		t.Fatalf("expected Bob's row to be quarantined, got %v", rows)
+	}
+}
@@ -0,0 +1,211 @@
+package emaildomainstats
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// defaultHTTPClient is used by WithCsvURL when WithHTTPClient is not given. http.DefaultClient has
+// no timeout, so a hung connection would otherwise block Process indefinitely.
+var defaultHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// HTTPOption is a function type used to configure the HTTP fetch performed by WithCsvURL.
+type HTTPOption func(*httpFetcher) error
+
+// WithCsvURL returns a ProcessorOption that fetches the CSV over HTTP from url and configures the
+// Processor to read from the response body, as if it had been passed to WithCsvReader. It is a
+// standalone source option: like WithZipArchive it must not be combined with WithCsvFileName or
+// WithCsvReader. WithGzip/WithBzip2/WithAutoDetectCompression may still be used afterward to
+// decompress the fetched body on the fly.
+func WithCsvURL(url string, opts ...HTTPOption) ProcessorOption {
+	return func(p *Processor) error {
+		f := &httpFetcher{client: defaultHTTPClient}
+		for _, opt := range opts {
+			if err := opt(f); err != nil {
+				return err
+			}
+		}
+
+		rc, err := f.fetch(url)
+		if err != nil {
+			return err
+		}
+		p.fileReader = rc
+		return nil
+	}
+}
+
+// WithHTTPClient returns an HTTPOption that overrides the *http.Client used by WithCsvURL. The
+// default is http.DefaultClient with its Timeout set to 30s.
+func WithHTTPClient(client *http.Client) HTTPOption {
+	return func(f *httpFetcher) error {
+		if client == nil {
+			return fmt.Errorf("HTTP client must not be nil")
+		}
+		f.client = client
+		return nil
+	}
+}
+
+// WithHTTPCache returns an HTTPOption that persists each fetched response body under dir, keyed by
+// the SHA-256 of the URL, along with its ETag/Last-Modified. Subsequent fetches of the same URL send
+// If-None-Match/If-Modified-Since and, on a 304, replay the cached body instead of re-downloading it
+// - useful for pipelines that re-run against a daily CSV drop that usually hasn't changed.
+func WithHTTPCache(dir string) HTTPOption {
+	return func(f *httpFetcher) error {
+		if dir == "" {
+			return fmt.Errorf("HTTP cache directory must not be empty")
+		}
+		f.cacheDir = dir
+		return nil
+	}
+}
+
+// httpFetcher holds the configuration for a single WithCsvURL fetch.
+type httpFetcher struct {
+	client   *http.Client
+	cacheDir string
+}
+
+// httpCacheMeta is the on-disk sidecar WithHTTPCache stores alongside a cached response body, used
+// to populate the conditional-request headers on the next fetch.
+type httpCacheMeta struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+// fetch performs the GET request for url, applying conditional-request headers and replaying the
+// cached body on a 304 if f.cacheDir is configured.
+func (f *httpFetcher) fetch(url string) (io.ReadCloser, error) {
+	if f.cacheDir == "" {
+		return f.request(url, httpCacheMeta{}, false)
+	}
+
+	bodyPath, metaPath := f.cachePaths(url)
+	meta, cached := readHTTPCacheMeta(metaPath)
+	if cached {
+		if _, err := os.Stat(bodyPath); err != nil {
+			cached = false
+		}
+	}
+
+	resp, err := f.requestResponse(url, meta, cached)
+	if err != nil {
+		return nil, err
+	}
+
+	if cached && resp.StatusCode == http.StatusNotModified {
+		resp.Body.Close()
+		return os.Open(bodyPath)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("http %d fetching %s", resp.StatusCode, url)
+	}
+	defer resp.Body.Close()
+
+	if err := os.MkdirAll(f.cacheDir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating HTTP cache directory %s: %w", f.cacheDir, err)
+	}
+	if err := cacheResponse(resp, f.cacheDir, bodyPath, metaPath); err != nil {
+		return nil, err
+	}
+	return os.Open(bodyPath)
+}
+
+// requestResponse builds and issues the GET for url, attaching conditional-request headers from meta
+// when sendConditional is true. The caller owns the returned response's body.
+func (f *httpFetcher) requestResponse(url string, meta httpCacheMeta, sendConditional bool) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("building request for %s: %w", url, err)
+	}
+	req.Header.Set("Accept", "text/csv, application/gzip")
+	if sendConditional {
+		if meta.ETag != "" {
+			req.Header.Set("If-None-Match", meta.ETag)
+		}
+		if meta.LastModified != "" {
+			req.Header.Set("If-Modified-Since", meta.LastModified)
+		}
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", url, err)
+	}
+	return resp, nil
+}
+
+// request performs an uncached GET, returning the response body on a 2xx status or an error
+// otherwise.
+func (f *httpFetcher) request(url string, meta httpCacheMeta, sendConditional bool) (io.ReadCloser, error) {
+	resp, err := f.requestResponse(url, meta, sendConditional)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("http %d fetching %s", resp.StatusCode, url)
+	}
+	return resp.Body, nil
+}
+
+// cacheResponse drains resp's body to bodyPath, atomically via a temp file in dir, and then writes
+// the ETag/Last-Modified sidecar directly to metaPath.
+func cacheResponse(resp *http.Response, dir, bodyPath, metaPath string) error {
+	tmp, err := os.CreateTemp(dir, "httpcache-*")
+	if err != nil {
+		return fmt.Errorf("creating HTTP cache file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, resp.Body); err != nil {
+		tmp.Close()
+		return fmt.Errorf("caching response body: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("closing HTTP cache file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), bodyPath); err != nil {
+		return fmt.Errorf("finalizing HTTP cache file: %w", err)
+	}
+
+	meta := httpCacheMeta{ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("encoding HTTP cache metadata: %w", err)
+	}
+	if err := os.WriteFile(metaPath, data, 0o644); err != nil {
+		return fmt.Errorf("writing HTTP cache metadata: %w", err)
+	}
+	return nil
+}
+
+// cachePaths returns the body and metadata file paths WithHTTPCache uses for url, keyed by its
+// SHA-256 digest so arbitrary URLs map to filesystem-safe names.
+func (f *httpFetcher) cachePaths(url string) (bodyPath, metaPath string) {
+	sum := sha256.Sum256([]byte(url))
+	key := hex.EncodeToString(sum[:])
+	return filepath.Join(f.cacheDir, key+".body"), filepath.Join(f.cacheDir, key+".meta")
+}
+
+// readHTTPCacheMeta loads the sidecar at metaPath, if present and well-formed.
+func readHTTPCacheMeta(metaPath string) (httpCacheMeta, bool) {
+	data, err := os.ReadFile(metaPath)
+	if err != nil {
+		return httpCacheMeta{}, false
+	}
+	var meta httpCacheMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return httpCacheMeta{}, false
+	}
+	return meta, true
+}
@@ -0,0 +1,128 @@
+package emaildomainstats_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	emaildomainstats "github.com/fcuenca/go-tech-test"
+)
+
+func TestProcessor_WithCsvURL(t *testing.T) {
+	content := "id,name,email\n1,Alice,alice@example.com\n2,Bob,bob@gmail.com\n"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(content))
+	}))
+	defer server.Close()
+
+	processor, err := emaildomainstats.New(
+		emaildomainstats.NewDomainStatTracker(),
+		emaildomainstats.WithCsvURL(server.URL),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+
+	result, err := processor.Process(context.Background())
+	if err != nil {
+		t.Fatalf("Process returned unexpected error: %v", err)
+	}
+
+	stats := result.Store.GetSorted()
+	if len(stats) != 2 {
+		t.Fatalf("expected 2 domains, got %d: %+v", len(stats), stats)
+	}
+}
+
+func TestProcessor_WithCsvURL_NonSuccessStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	_, err := emaildomainstats.New(
+		emaildomainstats.NewDomainStatTracker(),
+		emaildomainstats.WithCsvURL(server.URL),
+	)
+	if err == nil {
+		t.Fatal("expected an error for a non-2xx response")
+	}
+}
+
+func TestWithHTTPClient_Nil(t *testing.T) {
+	_, err := emaildomainstats.New(
+		emaildomainstats.NewDomainStatTracker(),
+		emaildomainstats.WithCsvURL("http://example.invalid/data.csv", emaildomainstats.WithHTTPClient(nil)),
+	)
+	if err == nil {
+		t.Fatal("expected an error for a nil HTTP client")
+	}
+}
+
+func TestWithHTTPCache_EmptyDir(t *testing.T) {
+	_, err := emaildomainstats.New(
+		emaildomainstats.NewDomainStatTracker(),
+		emaildomainstats.WithCsvURL("http://example.invalid/data.csv", emaildomainstats.WithHTTPCache("")),
+	)
+	if err == nil {
+		t.Fatal("expected an error for an empty HTTP cache directory")
+	}
+}
+
+// TestProcessor_WithCsvURL_CacheReplaysOn304 confirms that once a body has been cached, a 304
+// response (as a real conditional-caching server would return once it sees our ETag) is replayed
+// from disk rather than requiring the server to resend the body.
+func TestProcessor_WithCsvURL_CacheReplaysOn304(t *testing.T) {
+	content := "id,name,email\n1,Alice,alice@example.com\n2,Bob,bob@gmail.com\n"
+	const etag = `"v1"`
+	var requests int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Write([]byte(content))
+	}))
+	defer server.Close()
+
+	cacheDir := filepath.Join(t.TempDir(), "http-cache")
+
+	for i := 0; i < 2; i++ {
+		processor, err := emaildomainstats.New(
+			emaildomainstats.NewDomainStatTracker(),
+			emaildomainstats.WithCsvURL(server.URL, emaildomainstats.WithHTTPCache(cacheDir)),
+		)
+		if err != nil {
+			t.Fatalf("run %d: Failed to create processor: %v", i, err)
+		}
+
+		result, err := processor.Process(context.Background())
+		if err != nil {
+			t.Fatalf("run %d: Process returned unexpected error: %v", i, err)
+		}
+
+		stats := result.Store.GetSorted()
+		if len(stats) != 2 {
+			t.Fatalf("run %d: expected 2 domains, got %d: %+v", i, len(stats), stats)
+		}
+	}
+
+	if requests != 2 {
+		t.Fatalf("expected 2 requests to the server (one per run), got %d", requests)
+	}
+
+	entries, err := os.ReadDir(cacheDir)
+	if err != nil {
+		t.Fatalf("reading cache dir: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 cache files (body + meta), got %d", len(entries))
+	}
+}
@@ -0,0 +1,79 @@
+package emaildomainstats_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	emaildomainstats "github.com/fcuenca/go-tech-test"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestProcessor_WithMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	tracker := emaildomainstats.NewDomainStatTracker()
+	reader := io.NopCloser(strings.NewReader("id,name,email\n1,Alice,alice@example.com\n2,Bob,bobgmail.com"))
+
+	processor, err := emaildomainstats.New(
+		tracker,
+		emaildomainstats.WithCsvReader(reader),
+		emaildomainstats.WithConcurrency(1),
+		emaildomainstats.WithMetrics(reg),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+
+	if _, err := processor.Process(context.Background()); err != nil {
+		t.Fatalf("Process returned unexpected fatal error: %v", err)
+	}
+
+	metricFamilies, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather failed: %v", err)
+	}
+
+	var found bool
+	for _, mf := range metricFamilies {
+		if mf.GetName() == "emaildomainstats_rows_read_total" {
+			found = true
+			if got := mf.Metric[0].GetCounter().GetValue(); got != 2 {
+				t.Errorf("expected 2 rows read, got %v", got)
+			}
+		}
+	}
+	if !found {
+		t.Error("expected emaildomainstats_rows_read_total to be registered")
+	}
+}
+
+func TestDomainStatTracker_Collect(t *testing.T) {
+	tracker := emaildomainstats.NewDomainStatTracker()
+	tracker.Add("example.com")
+	tracker.Add("example.com")
+
+	reg := prometheus.NewRegistry()
+	if err := reg.Register(tracker); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	metricFamilies, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather failed: %v", err)
+	}
+
+	var metric *dto.Metric
+	for _, mf := range metricFamilies {
+		if mf.GetName() == "email_domain_count" {
+			metric = mf.Metric[0]
+		}
+	}
+	if metric == nil {
+		t.Fatal("expected email_domain_count to be collected")
+	}
+	if got := metric.GetGauge().GetValue(); got != 2 {
+		t.Errorf("expected count 2, got %v", got)
+	}
+}
@@ -0,0 +1,213 @@
+package emaildomainstats
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Processor run states, as reported in Status.State.
+const (
+	StateIdle     = "Idle"
+	StateRunning  = "Running"
+	StateStopping = "Stopping"
+	StateFinished = "Finished"
+	StateFailed   = "Failed"
+)
+
+// Status is a point-in-time snapshot of a Process/ProcessStream run, as returned by
+// Processor.Status and emitted periodically on the channel returned by Processor.Progress.
+type Status struct {
+	State         string
+	RecordsRead   int64
+	RecordsOK     int64
+	RecordsFailed int64
+	StartedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// WithProgressInterval returns a ProcessorOption that controls how often Processor.Progress
+// emits a snapshot, and how often WithCheckpoint persists one, in terms of records processed.
+// The default is every 1000 records.
+func WithProgressInterval(n int64) ProcessorOption {
+	return func(p *Processor) error {
+		if n < 1 {
+			return fmt.Errorf("progress interval must be at least 1")
+		}
+		p.status.interval = n
+		return nil
+	}
+}
+
+// Status returns a snapshot of the current (or most recently finished) run.
+func (p *Processor) Status() Status {
+	return p.status.snapshot()
+}
+
+// Progress returns a channel on which a Status snapshot is sent every WithProgressInterval
+// records. Sends are non-blocking: a slow or absent consumer misses intermediate snapshots
+// rather than stalling processing, so callers should treat Status as a cheap way to poll overall
+// progress rather than rely on every snapshot being delivered.
+func (p *Processor) Progress() <-chan Status {
+	return p.status.progress
+}
+
+// Stop requests that the current Process/ProcessStream run cancel as soon as possible and let
+// in-flight rows drain, the same as cancelling the context passed to it. It is safe to call at
+// any time, but only has an effect while a run is in progress: calling it before a run has started
+// or after one has finished does not affect the next run.
+func (p *Processor) Stop() {
+	p.status.requestStop()
+}
+
+// processorStatus backs Processor.Status/Stop/Progress, and drives WithCheckpoint's periodic
+// persistence. A zero processorStatus is not valid; use newProcessorStatus.
+type processorStatus struct {
+	recordsRead   atomic.Int64
+	recordsOK     atomic.Int64
+	recordsFailed atomic.Int64
+
+	interval int64
+	progress chan Status
+
+	mu        sync.Mutex
+	state     string
+	startedAt time.Time
+	updatedAt time.Time
+	cancel    func()
+}
+
+// newProcessorStatus returns an Idle processorStatus with the default progress interval.
+func newProcessorStatus() *processorStatus {
+	return &processorStatus{
+		state:    StateIdle,
+		interval: 1000,
+		progress: make(chan Status, 16),
+	}
+}
+
+// start marks a new run as begun, resuming RecordsRead from resumeFrom (0 unless WithCheckpoint
+// found a previous checkpoint to resume from) and resetting RecordsOK and RecordsFailed to 0.
+func (s *processorStatus) start(resumeFrom int64) {
+	s.recordsRead.Store(resumeFrom)
+	s.recordsOK.Store(0)
+	s.recordsFailed.Store(0)
+	s.resetCancel()
+
+	s.mu.Lock()
+	s.state = StateRunning
+	s.startedAt = time.Now()
+	s.updatedAt = s.startedAt
+	s.mu.Unlock()
+}
+
+// resetCancel clears any cancel function left over from a previous run.
+func (s *processorStatus) resetCancel() {
+	s.mu.Lock()
+	s.cancel = nil
+	s.mu.Unlock()
+}
+
+// setCancel records the function that Stop should call to cancel the in-progress run.
+func (s *processorStatus) setCancel(cancel func()) {
+	s.mu.Lock()
+	s.cancel = cancel
+	s.mu.Unlock()
+}
+
+// requestStop transitions a running status to Stopping and cancels it, if a run is in progress.
+func (s *processorStatus) requestStop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	if s.state == StateRunning {
+		s.state = StateStopping
+	}
+	s.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// finish marks the run as done: Failed if err is non-nil, Finished otherwise.
+func (s *processorStatus) finish(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err != nil {
+		s.state = StateFailed
+	} else {
+		s.state = StateFinished
+	}
+	s.updatedAt = time.Now()
+}
+
+// snapshot returns the current Status.
+func (s *processorStatus) snapshot() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return Status{
+		State:         s.state,
+		RecordsRead:   s.recordsRead.Load(),
+		RecordsOK:     s.recordsOK.Load(),
+		RecordsFailed: s.recordsFailed.Load(),
+		StartedAt:     s.startedAt,
+		UpdatedAt:     s.updatedAt,
+	}
+}
+
+// tick records one more terminal row outcome and reports whether this was the Nth record since
+// the last tick, i.e. whether the caller should emit a progress snapshot (and, if configured,
+// persist a checkpoint) now.
+func (s *processorStatus) tick(ok bool) bool {
+	if ok {
+		s.recordsOK.Add(1)
+	} else {
+		s.recordsFailed.Add(1)
+	}
+	n := s.recordsRead.Add(1)
+
+	s.mu.Lock()
+	s.updatedAt = time.Now()
+	s.mu.Unlock()
+
+	return n%s.interval == 0
+}
+
+// emitProgress sends the current status on the progress channel without blocking.
+func (s *processorStatus) emitProgress() {
+	status := s.snapshot()
+	select {
+	case s.progress <- status:
+	default:
+	}
+}
+
+// statusTick records a terminal row outcome for Status/Progress, and, on interval boundaries,
+// emits a progress snapshot and - if WithCheckpoint is configured - persists one.
+func (p *Processor) statusTick(ok bool) {
+	if !p.status.tick(ok) {
+		return
+	}
+
+	p.status.emitProgress()
+
+	if p.checkpointPath == "" {
+		return
+	}
+
+	snapshot := p.store.Snapshot()
+	domains := make([]checkpointDomain, len(snapshot))
+	for i, stat := range snapshot {
+		domains[i] = checkpointDomain{Domain: stat.Domain, Count: stat.Count()}
+	}
+
+	state := &checkpointState{
+		LastLineNumber: p.status.recordsRead.Load(),
+		Domains:        domains,
+	}
+	// Best-effort: a failed checkpoint write shouldn't abort an otherwise-healthy run.
+	state.save(p.checkpointPath)
+}
@@ -0,0 +1,235 @@
+package emaildomainstats_test
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	emaildomainstats "github.com/fcuenca/go-tech-test"
+)
+
+func TestProcessor_Status(t *testing.T) {
+	content := "id,name,email\n1,Alice,alice@example.com\n2,Bob,bob@gmail.com\n"
+
+	processor, err := emaildomainstats.New(
+		emaildomainstats.NewDomainStatTracker(),
+		emaildomainstats.WithCsvReader(io.NopCloser(strings.NewReader(content))),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+
+	if got := processor.Status().State; got != emaildomainstats.StateIdle {
+		t.Fatalf("expected initial state %q, got %q", emaildomainstats.StateIdle, got)
+	}
+
+	if _, err := processor.Process(context.Background()); err != nil {
+		t.Fatalf("Process returned unexpected error: %v", err)
+	}
+
+	status := processor.Status()
+	if status.State != emaildomainstats.StateFinished {
+		t.Errorf("expected state %q, got %q", emaildomainstats.StateFinished, status.State)
+	}
+	if status.RecordsRead != 2 || status.RecordsOK != 2 || status.RecordsFailed != 0 {
+		t.Errorf("unexpected record counts: %+v", status)
+	}
+}
+
+func TestProcessor_Progress(t *testing.T) {
+	var rows []string
+	for i := 0; i < 5; i++ {
+		rows = append(rows, "1,User,user@example.com")
+	}
+	content := "id,name,email\n" + strings.Join(rows, "\n") + "\n"
+
+	processor, err := emaildomainstats.New(
+		emaildomainstats.NewDomainStatTracker(),
+		emaildomainstats.WithCsvReader(io.NopCloser(strings.NewReader(content))),
+		emaildomainstats.WithConcurrency(1),
+		emaildomainstats.WithProgressInterval(2),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+
+	if _, err := processor.Process(context.Background()); err != nil {
+		t.Fatalf("Process returned unexpected error: %v", err)
+	}
+
+	select {
+	case status := <-processor.Progress():
+		if status.RecordsRead == 0 {
+			t.Errorf("expected a non-zero progress snapshot, got %+v", status)
+		}
+	default:
+		t.Fatal("expected at least one progress snapshot to have been emitted")
+	}
+}
+
+// blockingValidator is a DomainValidator that signals on entered the first time Validate is
+// called for each row, then blocks until ctx is cancelled. It lets tests deterministically wait
+// for a run to be mid-row before calling Processor.Stop, rather than racing real row throughput
+// with a sleep.
+type blockingValidator struct {
+	entered chan struct{}
+}
+
+func (v *blockingValidator) Validate(ctx context.Context, domain string) error {
+	select {
+	case v.entered <- struct{}{}:
+	default:
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestProcessor_Stop(t *testing.T) {
+	content := "id,name,email\n" +
+		"1,Alice,alice@example.com\n" +
+		"2,Bob,bob@gmail.com\n"
+
+	validator := &blockingValidator{entered: make(chan struct{}, 1)}
+	processor, err := emaildomainstats.New(
+		emaildomainstats.NewDomainStatTracker(),
+		emaildomainstats.WithCsvReader(io.NopCloser(strings.NewReader(content))),
+		emaildomainstats.WithConcurrency(1),
+		emaildomainstats.WithDomainValidator(validator),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := processor.Process(context.Background())
+		done <- err
+	}()
+
+	<-validator.entered
+	processor.Stop()
+
+	if err := <-done; err == nil {
+		t.Fatal("expected Process to return an error after Stop was called")
+	}
+
+	if got := processor.Status().State; got != emaildomainstats.StateFailed {
+		t.Errorf("expected state %q after a stopped run, got %q", emaildomainstats.StateFailed, got)
+	}
+}
+
+func TestProcessor_WithCheckpoint_Resume(t *testing.T) {
+	dir := t.TempDir()
+	checkpointPath := filepath.Join(dir, "checkpoint.json")
+
+	content := "id,name,email\n" +
+		"1,Alice,alice@example.com\n" +
+		"2,Bob,bob@gmail.com\n" +
+		"3,Carol,carol@example.org\n"
+
+	// releaseFirstRow lets alice's row finish (and get checkpointed) before Stop is called while
+	// the second row is validating, so the resumed run has exactly one already-committed domain to
+	// build on. Whether the reader also manages to read-ahead and have row 3 (carol) rejected by
+	// the same cancellation before Stop takes effect is a genuine, harmless race inherent to the
+	// pipeline (the reader always tries to read one row ahead of the row the worker is validating),
+	// so the assertions below only rely on outcomes that hold either way.
+	releaseFirstRow := make(chan struct{})
+	rowsEntered := make(chan struct{}, 3)
+	var callCount int
+	validator := domainValidatorFunc(func(ctx context.Context, domain string) error {
+		callCount++
+		rowsEntered <- struct{}{}
+		if callCount == 1 {
+			<-releaseFirstRow
+			return nil
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	first, err := emaildomainstats.New(
+		emaildomainstats.NewDomainStatTracker(),
+		emaildomainstats.WithCsvReader(io.NopCloser(strings.NewReader(content))),
+		emaildomainstats.WithConcurrency(1),
+		emaildomainstats.WithProgressInterval(1),
+		emaildomainstats.WithCheckpoint(checkpointPath),
+		emaildomainstats.WithDomainValidator(validator),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := first.Process(context.Background())
+		done <- err
+	}()
+
+	<-rowsEntered // row 1 (alice) has started validating
+	close(releaseFirstRow)
+	<-rowsEntered // row 2 (bob) has started validating; row 1 has committed by now
+	first.Stop()
+
+	if err := <-done; err == nil {
+		t.Fatal("expected the first run to be stopped before completing")
+	}
+
+	second, err := emaildomainstats.New(
+		emaildomainstats.NewDomainStatTracker(),
+		emaildomainstats.WithCsvReader(io.NopCloser(strings.NewReader(content))),
+		emaildomainstats.WithConcurrency(1),
+		emaildomainstats.WithCheckpoint(checkpointPath),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create resumed processor: %v", err)
+	}
+
+	result, err := second.Process(context.Background())
+	if err != nil {
+		t.Fatalf("resumed Process returned unexpected error: %v", err)
+	}
+
+	// All 3 rows are accounted for exactly once between the two runs: the combined RecordsRead
+	// always reconciles to the file length, whichever row the reader happened to have read ahead to
+	// when Stop took effect.
+	if got := second.Status().RecordsRead; got != 3 {
+		t.Errorf("expected the resumed run's RecordsRead to reconcile to 3, got %d", got)
+	}
+
+	stats := result.Store.GetSorted()
+	byDomain := make(map[string]int64, len(stats))
+	for _, s := range stats {
+		byDomain[s.Domain] = s.Count()
+	}
+
+	// alice@example.com always finishes validating (and gets checkpointed) before Stop is called.
+	if byDomain["example.com"] != 1 {
+		t.Errorf("expected example.com to be restored with count 1, got %+v", stats)
+	}
+	// bob@gmail.com's validation is always cancelled mid-flight by Stop, so it must never commit,
+	// either in the first run or - since an already-read row is skipped, not retried - on resume.
+	if _, ok := byDomain["gmail.com"]; ok {
+		t.Errorf("expected gmail.com to never be recorded, got %+v", stats)
+	}
+}
+
+func TestProcessor_WithCheckpoint_RejectsConcurrency(t *testing.T) {
+	_, err := emaildomainstats.New(
+		emaildomainstats.NewDomainStatTracker(),
+		emaildomainstats.WithCsvReader(io.NopCloser(strings.NewReader(""))),
+		emaildomainstats.WithConcurrency(2),
+		emaildomainstats.WithCheckpoint(filepath.Join(t.TempDir(), "checkpoint.json")),
+	)
+	if err == nil {
+		t.Fatal("expected an error combining WithCheckpoint with WithConcurrency(2)")
+	}
+}
+
+// domainValidatorFunc adapts a function to the DomainValidator interface.
+type domainValidatorFunc func(ctx context.Context, domain string) error
+
+func (f domainValidatorFunc) Validate(ctx context.Context, domain string) error {
+	return f(ctx, domain)
+}
@@ -0,0 +1,216 @@
+package emaildomainstats
+
+import (
+	"container/heap"
+	"hash/maphash"
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// sketchEntry is a heavy-hitter candidate tracked by SketchTracker's bounded heap.
+type sketchEntry struct {
+	Domain string
+	count  int64
+	index  int // heap index
+}
+
+// sketchHeap is a min-heap of sketchEntry pointers ordered by estimated count, used to keep only
+// the topK heaviest hitters.
+type sketchHeap []*sketchEntry
+
+func (h sketchHeap) Len() int           { return len(h) }
+func (h sketchHeap) Less(i, j int) bool { return h[i].count < h[j].count }
+func (h sketchHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *sketchHeap) Push(x interface{}) {
+	item := x.(*sketchEntry)
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *sketchHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	item.index = -1
+	*h = old[:n-1]
+	return item
+}
+
+// SketchTracker is an approximate alternative to DomainStatTracker for workloads where the CSV
+// contains tens of millions of distinct domains (scraped mailing lists, botnet dumps) and the
+// O(unique-domains) memory of a map-per-domain tracker is untenable. It backs counts with a
+// Count-Min Sketch - width counters per row, depth independent hash rows - and keeps only a
+// bounded top-K heap of heavy hitters, so memory is O(width*depth + topK) regardless of
+// cardinality.
+//
+// Counts are approximate with the standard CMS guarantee: for true count t and estimate est,
+// t <= est <= t + Overestimate() with probability at least 1 - e^-depth, where Overestimate is
+// (e/width)*N and N is the total number of Add calls.
+type SketchTracker struct {
+	width int
+	depth int
+	topK  int
+
+	seeds  []maphash.Seed
+	counts [][]atomic.Uint32
+
+	mu    sync.Mutex
+	heap  sketchHeap
+	index map[string]*sketchEntry // domain -> its entry currently in heap, guarded by mu
+
+	total atomic.Int64
+}
+
+// NewSketchTracker returns a SketchTracker with depth independent hash rows of width counters
+// each, retaining the topK heaviest hitters. Recommended defaults for a billion-row stream are
+// width=2^20, depth=5, topK=10_000 (~20MB, sub-1% error); non-positive arguments fall back to
+// those defaults.
+func NewSketchTracker(width, depth, topK int) *SketchTracker {
+	if width <= 0 {
+		width = 1 << 20
+	}
+	if depth <= 0 {
+		depth = 5
+	}
+	if topK <= 0 {
+		topK = 10_000
+	}
+
+	seeds := make([]maphash.Seed, depth)
+	counts := make([][]atomic.Uint32, depth)
+	for row := 0; row < depth; row++ {
+		seeds[row] = maphash.MakeSeed()
+		counts[row] = make([]atomic.Uint32, width)
+	}
+
+	return &SketchTracker{
+		width:  width,
+		depth:  depth,
+		topK:   topK,
+		seeds:  seeds,
+		counts: counts,
+		index:  make(map[string]*sketchEntry),
+	}
+}
+
+// Add increments the counter for the given domain by 1.
+// concurrent safe.
+func (t *SketchTracker) Add(domain string) {
+	t.total.Add(1)
+
+	estimate := uint32(math.MaxUint32)
+	for row := 0; row < t.depth; row++ {
+		col := t.hash(row, domain) % uint64(t.width)
+		n := t.counts[row][col].Add(1)
+		if n < estimate {
+			estimate = n
+		}
+	}
+
+	t.trackHeavyHitter(domain, int64(estimate))
+}
+
+// hash computes the row-th independent hash of domain.
+func (t *SketchTracker) hash(row int, domain string) uint64 {
+	var h maphash.Hash
+	h.SetSeed(t.seeds[row])
+	h.WriteString(domain)
+	return h.Sum64()
+}
+
+// trackHeavyHitter updates the bounded top-K heap with domain's latest estimate, evicting the
+// current minimum when the heap is full and a heavier hitter is found.
+func (t *SketchTracker) trackHeavyHitter(domain string, estimate int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if entry, ok := t.index[domain]; ok {
+		entry.count = estimate
+		heap.Fix(&t.heap, entry.index)
+		return
+	}
+
+	if t.heap.Len() < t.topK {
+		entry := &sketchEntry{Domain: domain, count: estimate}
+		heap.Push(&t.heap, entry)
+		t.index[domain] = entry
+		return
+	}
+
+	if t.heap[0].count < estimate {
+		evicted := heap.Pop(&t.heap).(*sketchEntry)
+		delete(t.index, evicted.Domain)
+
+		entry := &sketchEntry{Domain: domain, count: estimate}
+		heap.Push(&t.heap, entry)
+		t.index[domain] = entry
+	}
+}
+
+// Snapshot returns the current top-K heavy hitters, sorted alphabetically by domain, without
+// mutating the heap.
+func (t *SketchTracker) Snapshot() []DomainStat {
+	t.mu.Lock()
+	entries := make([]*sketchEntry, len(t.heap))
+	copy(entries, t.heap)
+	t.mu.Unlock()
+
+	result := make([]DomainStat, len(entries))
+	for i, entry := range entries {
+		result[i] = DomainStat{Domain: entry.Domain, count: entry.count}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Domain < result[j].Domain })
+
+	return result
+}
+
+// GetSorted is a backward-compatible alias for Snapshot, returning only the tracked top-K heavy
+// hitters rather than every domain ever seen.
+func (t *SketchTracker) GetSorted() []DomainStat {
+	return t.Snapshot()
+}
+
+// Top returns up to n of the tracked heavy hitters ordered by by. Since SketchTracker only ever
+// retains topK heavy hitters, n is implicitly capped at topK.
+func (t *SketchTracker) Top(n int, by TopOrder) []DomainStat {
+	if n <= 0 {
+		return nil
+	}
+
+	result := t.Snapshot() // already sorted alphabetically
+
+	switch by {
+	case ByDomainAsc:
+	default:
+		sort.Slice(result, func(i, j int) bool {
+			if result[i].count != result[j].count {
+				return result[i].count > result[j].count
+			}
+			return result[i].Domain < result[j].Domain
+		})
+	}
+
+	if n < len(result) {
+		result = result[:n]
+	}
+
+	return result
+}
+
+// Overestimate returns the current Count-Min Sketch error bound, epsilon*N, where
+// epsilon = e/width and N is the total number of Add calls so far. Every estimated count returned
+// by Snapshot/GetSorted/Top satisfies true <= est <= true + Overestimate() with probability at
+// least 1 - e^-depth.
+func (t *SketchTracker) Overestimate() float64 {
+	epsilon := math.E / float64(t.width)
+	return epsilon * float64(t.total.Load())
+}
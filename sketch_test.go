@@ -0,0 +1,50 @@
+package emaildomainstats_test
+
+import (
+	"testing"
+
+	emaildomainstats "github.com/fcuenca/go-tech-test"
+)
+
+func TestSketchTracker_TracksHeavyHitters(t *testing.T) {
+	tracker := emaildomainstats.NewSketchTracker(1<<10, 5, 2)
+
+	for i := 0; i < 100; i++ {
+		tracker.Add("gmail.com")
+	}
+	for i := 0; i < 50; i++ {
+		tracker.Add("yahoo.com")
+	}
+	for i := 0; i < 3; i++ {
+		tracker.Add("rare.com")
+	}
+
+	stats := tracker.Top(2, emaildomainstats.ByCountDesc)
+	if len(stats) != 2 {
+		t.Fatalf("expected 2 heavy hitters, got %d", len(stats))
+	}
+	if stats[0].Domain != "gmail.com" || stats[0].Count() < 100 {
+		t.Errorf("expected gmail.com with count >= 100 first, got %+v", stats[0])
+	}
+	if stats[1].Domain != "yahoo.com" || stats[1].Count() < 50 {
+		t.Errorf("expected yahoo.com with count >= 50 second, got %+v", stats[1])
+	}
+}
+
+func TestSketchTracker_OverestimateGrowsWithVolume(t *testing.T) {
+	tracker := emaildomainstats.NewSketchTracker(1<<10, 5, 10)
+
+	before := tracker.Overestimate()
+	for i := 0; i < 1000; i++ {
+		tracker.Add("example.com")
+	}
+	after := tracker.Overestimate()
+
+	if after <= before {
+		t.Errorf("expected overestimate bound to grow with volume, got before=%v after=%v", before, after)
+	}
+}
+
+func TestSketchTracker_SatisfiesStatTracker(t *testing.T) {
+	var _ emaildomainstats.StatTracker = emaildomainstats.NewSketchTracker(0, 0, 0)
+}
@@ -0,0 +1,399 @@
+package emaildomainstats
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// legacyEmailColumnIndex is the email column index used when neither WithEmailColumn nor
+// WithEmailColumnIndex is configured, matching the column layout of customer_data.csv.
+const legacyEmailColumnIndex = 2
+
+// StreamOptions configures ProcessStream.
+type StreamOptions struct {
+	// Interval, if non-zero, invokes OnProgress at least once per wall-clock interval while rows
+	// are still being processed.
+	Interval time.Duration
+	// EveryNRows, if non-zero, invokes OnProgress every time that many additional rows have been
+	// processed, successfully or not.
+	EveryNRows int64
+	// OnProgress is invoked with the cumulative row count and a non-destructive snapshot of the
+	// current domain stats (see DomainStatTracker.Snapshot). It may be invoked concurrently by
+	// both the row counter and the interval ticker, and should not block for long.
+	OnProgress func(rowsProcessed int64, snapshot []DomainStat)
+	// OnError is invoked synchronously for every row-level error. Returning false aborts
+	// processing by tearing down the workers via the errgroup context, the same as a fatal
+	// reader error would.
+	OnError func(err error) (cont bool)
+}
+
+// progressTracker drives a StreamOptions' OnProgress calls for a single ProcessStream invocation.
+// Count-based ticks fire exactly every EveryNRows rows; wall-clock ticks are driven separately by
+// runTicker. recordRow and runTicker do nothing on a nil *progressTracker; current and emit
+// require a non-nil one.
+type progressTracker struct {
+	opts  StreamOptions
+	store StatTracker
+
+	mu   sync.Mutex
+	rows int64
+}
+
+// recordRow marks one more row as processed and fires a count-based tick if EveryNRows was
+// reached.
+func (t *progressTracker) recordRow() {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	t.rows++
+	n := t.rows
+	t.mu.Unlock()
+
+	if t.opts.EveryNRows > 0 && n%t.opts.EveryNRows == 0 {
+		t.emit(n)
+	}
+}
+
+// current returns the number of rows processed so far.
+func (t *progressTracker) current() int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.rows
+}
+
+// emit invokes OnProgress, if configured, with a fresh snapshot.
+func (t *progressTracker) emit(n int64) {
+	if t.opts.OnProgress != nil {
+		t.opts.OnProgress(n, t.store.Snapshot())
+	}
+}
+
+// runTicker emits wall-clock progress ticks until ctx is done. It is safe to call on a nil
+// tracker or with a zero Interval, in which case it returns immediately.
+func (t *progressTracker) runTicker(ctx context.Context) {
+	if t == nil || t.opts.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(t.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			t.emit(t.current())
+		}
+	}
+}
+
+// csvSource pairs a csv.Reader with the name of the archive entry it was read from, used to tag
+// per-entry errors when processing a ZIP archive. name is empty for non-archive input.
+type csvSource struct {
+	name   string
+	reader *csv.Reader
+	closer io.Closer // non-nil for archive entries, which must be closed once consumed
+}
+
+// newCsvReader builds a csv.Reader with the lenient settings used throughout this package.
+func newCsvReader(r io.Reader) *csv.Reader {
+	rd := csv.NewReader(r)
+	rd.FieldsPerRecord = -1
+	rd.LazyQuotes = true
+	return rd
+}
+
+// csvSources returns the CSV source(s) to read: every *.csv entry of p.archive, in archive order,
+// or else a single source backed by p.fileReader.
+func (p *Processor) csvSources() ([]csvSource, error) {
+	if p.archive != nil {
+		return p.csvEntries()
+	}
+	return []csvSource{{reader: newCsvReader(p.fileReader)}}, nil
+}
+
+// ProcessStream processes the CSV data incrementally, invoking opts.OnProgress as rows are
+// processed and opts.OnError synchronously for each row-level error. Unlike Process, it never
+// accumulates a Result: callers observe state entirely through the callbacks and the returned
+// error, which is non-nil only when processing was aborted (reader failure, an OnError callback
+// returning false, or context cancellation) rather than merely completing with row-level errors.
+func (p *Processor) ProcessStream(ctx context.Context, opts StreamOptions) error {
+	defer p.closeSource()
+
+	sources, err := p.csvSources()
+	if err != nil {
+		return err
+	}
+
+	resumeFrom, err := p.loadResumeState()
+	if err != nil {
+		return err
+	}
+	p.status.start(resumeFrom)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	p.status.setCancel(cancel)
+
+	g, gctx := errgroup.WithContext(ctx)
+
+	emailCh := make(chan emailRecord)
+	errorCh := make(chan error)
+
+	tracker := &progressTracker{opts: opts, store: p.store}
+
+	errDone := make(chan struct{})
+	go func() {
+		defer close(errDone)
+		for err := range errorCh {
+			if opts.OnError != nil && !opts.OnError(err) {
+				cancel()
+			}
+		}
+	}()
+
+	tickerCtx, stopTicker := context.WithCancel(gctx)
+	defer stopTicker()
+
+	tickerDone := make(chan struct{})
+	go func() {
+		defer close(tickerDone)
+		tracker.runTicker(tickerCtx)
+	}()
+
+	g.Go(func() error {
+		return p.reader(gctx, sources, emailCh, errorCh, tracker)
+	})
+
+	for i := 0; i < p.concurrency; i++ {
+		g.Go(func() error {
+			return p.worker(gctx, emailCh, errorCh, tracker)
+		})
+	}
+
+	groupErr := g.Wait()
+	close(errorCh)
+	<-errDone
+	stopTicker()
+	<-tickerDone
+
+	tracker.emit(tracker.current())
+
+	runErr := groupErr
+	if runErr == nil {
+		runErr = ctx.Err()
+	}
+	p.status.finish(runErr)
+
+	return runErr
+}
+
+// reader reads records from each source in turn and sends them to the emailCh channel, as if all
+// sources were concatenated. Row-level errors (malformed CSV records, missing per-entry headers)
+// are reported on the errorCh channel. A non-nil return indicates a fatal I/O failure (e.g. a
+// closed pipe) that should cancel sibling workers.
+func (p *Processor) reader(ctx context.Context, sources []csvSource, emailCh chan<- emailRecord, errorCh chan<- error, tracker *progressTracker) error {
+	defer close(emailCh)
+
+	for _, src := range sources {
+		err := p.readSource(ctx, src, emailCh, errorCh, tracker)
+		if src.closer != nil {
+			src.closer.Close()
+		}
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// readSource skips src's header row (unless WithoutHeader was used) and streams its records to
+// emailCh. A malformed or missing header is reported as a row-level error (tagged with src.name
+// when processing an archive entry) rather than aborting the whole reader, so one bad entry in a
+// ZIP archive doesn't stop the rest from being processed. A header present but missing the
+// configured email column (WithEmailColumn) is, by contrast, a fatal error: it almost always means
+// every row in this source would silently resolve to the wrong column, which is worse than
+// aborting.
+func (p *Processor) readSource(ctx context.Context, src csvSource, emailCh chan<- emailRecord, errorCh chan<- error, tracker *progressTracker) error {
+	emailIdx := legacyEmailColumnIndex
+	if p.emailColumnIndex >= 0 {
+		emailIdx = p.emailColumnIndex
+	}
+
+	if p.skipHeader {
+		header, err := src.reader.Read()
+		if err != nil {
+			p.recordRejected(reasonParseError)
+			tracker.recordRow()
+			rowErr := tagEntry(src.name, fmt.Errorf("error reading CSV header: %w", err))
+			if p.reportRowError(0, nil, rowErr, errorCh) {
+				return rowErr
+			}
+			return nil
+		}
+
+		if p.emailColumn != "" {
+			idx, err := resolveEmailColumn(header, p.emailColumn, p.headerNormalizer)
+			if err != nil {
+				return tagEntry(src.name, err)
+			}
+			emailIdx = idx
+		}
+	}
+
+	lineNumber := 1
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+			record, err := src.reader.Read()
+			if err == io.EOF {
+				return nil
+			}
+
+			if p.resumeSkip > 0 {
+				p.resumeSkip--
+				lineNumber++
+				continue
+			}
+
+			p.recordRead()
+
+			if err != nil {
+				var parseErr *csv.ParseError
+				if !errors.As(err, &parseErr) {
+					return tagEntry(src.name, fmt.Errorf("error reading CSV at line %d: %w", lineNumber, err))
+				}
+				p.recordRejected(reasonParseError)
+				tracker.recordRow()
+				p.statusTick(false)
+				rowErr := tagEntry(src.name, fmt.Errorf("error reading CSV at line %d: %w", lineNumber, err))
+				if p.reportRowError(lineNumber, record, rowErr, errorCh) {
+					return rowErr
+				}
+				lineNumber++
+				continue
+			}
+
+			if len(record) <= emailIdx || record[emailIdx] == "" {
+				p.recordRejected(reasonMissingEmail)
+				tracker.recordRow()
+				p.statusTick(false)
+				rowErr := tagEntry(src.name, fmt.Errorf("missing or empty email at line %d", lineNumber))
+				if p.reportRowError(lineNumber, record, rowErr, errorCh) {
+					return rowErr
+				}
+				lineNumber++
+				continue
+			}
+
+			select {
+			case emailCh <- emailRecord{lineNumber: lineNumber, raw: record, email: record[emailIdx]}:
+				p.setQueueDepth(len(emailCh))
+			case <-ctx.Done():
+				p.recordRejected(reasonContextCancelled)
+				tracker.recordRow()
+				p.statusTick(false)
+				return nil
+			}
+			lineNumber++
+		}
+	}
+}
+
+// tagEntry prefixes err with the archive entry name it came from, if any.
+func tagEntry(name string, err error) error {
+	if name == "" {
+		return err
+	}
+	return fmt.Errorf("%s: %w", name, err)
+}
+
+// worker reads from emailCh and processes emails. Row-level errors are reported on errorCh and
+// do not cause the worker to return an error, unless a configured ErrorHandler asks to stop.
+func (p *Processor) worker(ctx context.Context, emailCh <-chan emailRecord, errorCh chan<- error, tracker *progressTracker) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case rec, ok := <-emailCh:
+			if !ok {
+				return nil
+			}
+
+			start := time.Now()
+
+			domain, err := extractDomain(strings.ToLower(rec.email))
+			if err != nil {
+				p.recordRejected(reasonParseError)
+				tracker.recordRow()
+				p.statusTick(false)
+				rowErr := fmt.Errorf("extracting domain: (%s): %w", rec.email, err)
+				if p.reportRowError(rec.lineNumber, rec.raw, rowErr, errorCh) {
+					return rowErr
+				}
+				continue
+			}
+
+			if err := p.validator.Validate(ctx, domain); err != nil {
+				p.recordRejected(reasonValidatorRejected)
+				tracker.recordRow()
+				p.statusTick(false)
+				rowErr := fmt.Errorf("validating domain (%s): %w", domain, err)
+				if p.reportRowError(rec.lineNumber, rec.raw, rowErr, errorCh) {
+					return rowErr
+				}
+				continue
+			}
+
+			p.store.Add(domain)
+			p.recordOK()
+			p.observeRowLatency(time.Since(start))
+			tracker.recordRow()
+			p.statusTick(true)
+		}
+	}
+}
+
+// emailRecord is a single CSV row as handed off from reader to worker: the email field worker
+// needs to process, plus enough of the original row (lineNumber, raw) to pass on to an
+// ErrorHandler/quarantine writer if something goes wrong downstream.
+type emailRecord struct {
+	lineNumber int
+	raw        []string
+	email      string
+}
+
+// reportRowError writes raw to the quarantine writer (if configured) and runs the configured
+// ErrorHandler (if any), in addition to err always being sent to errorCh as before. It returns
+// true if the handler asked to stop, in which case the caller should return err immediately to
+// cancel sibling goroutines via the errgroup.
+func (p *Processor) reportRowError(lineNumber int, raw []string, err error, errorCh chan<- error) bool {
+	if p.quarantine != nil {
+		p.quarantine.write(raw)
+	}
+
+	errorCh <- err
+
+	if p.errorHandler == nil {
+		return false
+	}
+	return p.errorHandler(lineNumber, raw, err)
+}
@@ -0,0 +1,82 @@
+package emaildomainstats_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	emaildomainstats "github.com/fcuenca/go-tech-test"
+)
+
+func TestProcessor_ProcessStream_Progress(t *testing.T) {
+	content := "id,name,email\n" +
+		"1,Alice,alice@example.com\n" +
+		"2,Bob,bobgmail.com\n" +
+		"3,Charlie,charlie@example.com\n" +
+		"4,David,david@yahoo.com"
+
+	reader := io.NopCloser(strings.NewReader(content))
+	processor, err := emaildomainstats.New(
+		emaildomainstats.NewDomainStatTracker(),
+		emaildomainstats.WithCsvReader(reader),
+		emaildomainstats.WithConcurrency(1),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+
+	var progressCalls []int64
+	var rowErrors []error
+
+	err = processor.ProcessStream(context.Background(), emaildomainstats.StreamOptions{
+		EveryNRows: 1,
+		OnProgress: func(rowsProcessed int64, snapshot []emaildomainstats.DomainStat) {
+			progressCalls = append(progressCalls, rowsProcessed)
+		},
+		OnError: func(err error) bool {
+			rowErrors = append(rowErrors, err)
+			return true
+		},
+	})
+	if err != nil {
+		t.Fatalf("ProcessStream returned unexpected error: %v", err)
+	}
+
+	// One call per row, plus a final call once processing completes.
+	if len(progressCalls) != 5 {
+		t.Errorf("expected 5 progress calls, got %d: %v", len(progressCalls), progressCalls)
+	}
+	if last := progressCalls[len(progressCalls)-1]; last != 4 {
+		t.Errorf("expected final progress call to report 4 rows, got %d", last)
+	}
+	if len(rowErrors) != 1 {
+		t.Errorf("expected 1 row error, got %d: %v", len(rowErrors), rowErrors)
+	}
+}
+
+func TestProcessor_ProcessStream_OnErrorAborts(t *testing.T) {
+	content := "id,name,email\n" +
+		"1,Alice,alice@example.com\n" +
+		"2,Bob,bobgmail.com\n" +
+		"3,Charlie,charliegmail.com\n"
+
+	reader := io.NopCloser(strings.NewReader(content))
+	processor, err := emaildomainstats.New(
+		emaildomainstats.NewDomainStatTracker(),
+		emaildomainstats.WithCsvReader(reader),
+		emaildomainstats.WithConcurrency(1),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create processor: %v", err)
+	}
+
+	err = processor.ProcessStream(context.Background(), emaildomainstats.StreamOptions{
+		OnError: func(err error) bool {
+			return false
+		},
+	})
+	if err == nil {
+		t.Fatal("expected ProcessStream to return an error when OnError aborts")
+	}
+}
@@ -2,25 +2,57 @@ package emaildomainstats
 
 import (
 	"container/heap"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
 )
 
-// DomainStat represents statistics for a single domain
+// StatTracker is the interface Processor uses to record observed domains. DomainStatTracker
+// tracks every domain exactly; SketchTracker trades exactness for bounded memory on workloads
+// with effectively unbounded cardinality. Both implement this interface, so a Processor can be
+// pointed at either without caring which.
+type StatTracker interface {
+	// Add increments the counter for the given domain by 1. Must be safe for concurrent use.
+	Add(domain string)
+	// Snapshot returns a non-destructive, point-in-time copy of the tracked domains.
+	Snapshot() []DomainStat
+	// GetSorted is a backward-compatible alias for Snapshot.
+	GetSorted() []DomainStat
+}
+
+// DomainStat is a point-in-time snapshot of a single domain's count, as returned by Snapshot,
+// GetSorted and Top. It is an immutable value: copying it is safe.
 type DomainStat struct {
 	Domain string
-	count  atomic.Int64
-	index  int // heap index
+	count  int64
 }
 
-// Count retrieves the domain total count
-func (d *DomainStat) Count() int64 {
-	return d.count.Load()
+// Count retrieves the domain's count as of when the snapshot was taken.
+func (d DomainStat) Count() int64 {
+	return d.count
+}
+
+// TopOrder selects the ordering used by DomainStatTracker.Top.
+type TopOrder int
+
+const (
+	// ByCountDesc orders results by count, highest first, breaking ties alphabetically.
+	ByCountDesc TopOrder = iota
+	// ByDomainAsc orders results alphabetically by domain, ascending.
+	ByDomainAsc
+)
+
+// domainEntry is the live, mutable heap item backing a tracked domain. It is never exposed
+// outside the package; callers only ever see DomainStat snapshots.
+type domainEntry struct {
+	Domain string
+	count  atomic.Int64
+	index  int // heap index
 }
 
-// StatHeap is a min-heap of DomainStat pointers, ordered alphabetically by Domain
-type StatHeap []*DomainStat
+// StatHeap is a min-heap of domainEntry pointers, ordered alphabetically by Domain
+type StatHeap []*domainEntry
 
 // Len returns the number of elements in the heap
 func (h StatHeap) Len() int { return len(h) }
@@ -38,7 +70,7 @@ func (h StatHeap) Swap(i, j int) {
 // Push adds an element to the heap and maintains the heap invariant
 func (h *StatHeap) Push(x interface{}) {
 	n := len(*h)
-	item := x.(*DomainStat)
+	item := x.(*domainEntry)
 	item.index = n
 	*h = append(*h, item)
 }
@@ -92,28 +124,107 @@ func NewDomainStatTracker(opts ...Option) *DomainStatTracker {
 // Add increments the counter for the given domain by 1.
 // concurrent safe.
 func (dst *DomainStatTracker) Add(domain string) {
-	actual, loaded := dst.cache.LoadOrStore(domain, &DomainStat{Domain: domain})
-	stat := actual.(*DomainStat)
-	stat.count.Add(1)
+	actual, loaded := dst.cache.LoadOrStore(domain, &domainEntry{Domain: domain})
+	entry := actual.(*domainEntry)
+	entry.count.Add(1)
 	if !loaded {
 		// New domain: needs to be added to heap (synchronized)
 		dst.mu.Lock()
-		heap.Push(dst.heap, stat)
+		heap.Push(dst.heap, entry)
 		dst.mu.Unlock()
 	}
 }
 
+// Snapshot returns a value copy of every tracked domain's current count, sorted alphabetically
+// by domain. Unlike GetSorted historically did, Snapshot never mutates the underlying heap, so it
+// is safe to call repeatedly - e.g. from a progress reporter or an HTTP handler - while Add
+// continues to be called concurrently.
+func (dst *DomainStatTracker) Snapshot() []DomainStat {
+	dst.mu.Lock()
+	entries := make([]*domainEntry, len(*dst.heap))
+	copy(entries, *dst.heap)
+	dst.mu.Unlock()
+
+	result := make([]DomainStat, len(entries))
+	for i, entry := range entries {
+		result[i] = DomainStat{Domain: entry.Domain, count: entry.count.Load()}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Domain < result[j].Domain })
+
+	return result
+}
+
 // GetSorted returns a list of domains with their counts, sorted alphabetically by domain.
-// This method has a time complexity of O(n log n) and space complexity of O(n),
-// where n is the number of unique domains.
-func (dst *DomainStatTracker) GetSorted() []*DomainStat {
+// It is kept for backward compatibility and is now a thin, non-destructive wrapper around
+// Snapshot; prefer calling Snapshot directly in new code.
+func (dst *DomainStatTracker) GetSorted() []DomainStat {
+	return dst.Snapshot()
+}
+
+// topHeap is a bounded min-heap used by Top to keep only the n entries worth retaining, evicting
+// the "worst" one (per less) whenever a better candidate is found.
+type topHeap struct {
+	items []DomainStat
+	less  func(a, b DomainStat) bool
+}
+
+func (h topHeap) Len() int            { return len(h.items) }
+func (h topHeap) Less(i, j int) bool  { return h.less(h.items[i], h.items[j]) }
+func (h topHeap) Swap(i, j int)       { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *topHeap) Push(x interface{}) { h.items = append(h.items, x.(DomainStat)) }
+func (h *topHeap) Pop() interface{} {
+	old := h.items
+	n := len(old)
+	item := old[n-1]
+	h.items = old[:n-1]
+	return item
+}
+
+// Top returns the n most significant domains ordered by by, computed in O(m log n) time (m being
+// the total number of tracked domains) via a bounded min-heap rather than sorting everything.
+func (dst *DomainStatTracker) Top(n int, by TopOrder) []DomainStat {
+	if n <= 0 {
+		return nil
+	}
+
+	// less(a, b) reports whether a is a worse candidate than b, i.e. the one to evict first.
+	var less func(a, b DomainStat) bool
+	switch by {
+	case ByDomainAsc:
+		less = func(a, b DomainStat) bool { return a.Domain > b.Domain }
+	default:
+		less = func(a, b DomainStat) bool { return a.count < b.count }
+	}
+
+	h := &topHeap{less: less}
+
 	dst.mu.Lock()
-	defer dst.mu.Unlock()
+	entries := make([]*domainEntry, len(*dst.heap))
+	copy(entries, *dst.heap)
+	dst.mu.Unlock()
+
+	for _, entry := range entries {
+		candidate := DomainStat{Domain: entry.Domain, count: entry.count.Load()}
+		if h.Len() < n {
+			heap.Push(h, candidate)
+		} else if less(h.items[0], candidate) {
+			h.items[0] = candidate
+			heap.Fix(h, 0)
+		}
+	}
 
-	result := make([]*DomainStat, 0, len(*dst.heap))
-	for dst.heap.Len() > 0 {
-		item := heap.Pop(dst.heap).(*DomainStat)
-		result = append(result, item)
+	result := h.items
+	switch by {
+	case ByDomainAsc:
+		sort.Slice(result, func(i, j int) bool { return result[i].Domain < result[j].Domain })
+	default:
+		sort.Slice(result, func(i, j int) bool {
+			if result[i].count != result[j].count {
+				return result[i].count > result[j].count
+			}
+			return result[i].Domain < result[j].Domain
+		})
 	}
 
 	return result
@@ -7,6 +7,55 @@ import (
 	"testing"
 )
 
+func Test_DomainStatTracker_SnapshotIsNonDestructive(t *testing.T) {
+	tracker := emaildomainstats.NewDomainStatTracker()
+	tracker.Add("example.com")
+	tracker.Add("gmail.com")
+	tracker.Add("example.com")
+
+	first := tracker.Snapshot()
+	second := tracker.Snapshot()
+
+	if len(first) != 2 || len(second) != 2 {
+		t.Fatalf("expected 2 domains on each snapshot, got %d then %d", len(first), len(second))
+	}
+
+	tracker.Add("yahoo.com")
+	third := tracker.Snapshot()
+	if len(third) != 3 {
+		t.Fatalf("expected 3 domains after adding a new one, got %d", len(third))
+	}
+}
+
+func Test_DomainStatTracker_Top(t *testing.T) {
+	tracker := emaildomainstats.NewDomainStatTracker()
+	counts := map[string]int{"a.com": 5, "b.com": 1, "c.com": 3, "d.com": 9, "e.com": 2}
+	for domain, n := range counts {
+		for i := 0; i < n; i++ {
+			tracker.Add(domain)
+		}
+	}
+
+	byCount := tracker.Top(3, emaildomainstats.ByCountDesc)
+	wantByCount := []string{"d.com", "a.com", "c.com"}
+	if len(byCount) != len(wantByCount) {
+		t.Fatalf("expected %d results, got %d", len(wantByCount), len(byCount))
+	}
+	for i, domain := range wantByCount {
+		if byCount[i].Domain != domain {
+			t.Errorf("position %d: expected %s, got %s", i, domain, byCount[i].Domain)
+		}
+	}
+
+	byDomain := tracker.Top(3, emaildomainstats.ByDomainAsc)
+	wantByDomain := []string{"a.com", "b.com", "c.com"}
+	for i, domain := range wantByDomain {
+		if byDomain[i].Domain != domain {
+			t.Errorf("position %d: expected %s, got %s", i, domain, byDomain[i].Domain)
+		}
+	}
+}
+
 // customLocker is a wrapper around sync.Mutex that counts lock acquisitions
 type customLocker struct {
 	mu         sync.Mutex
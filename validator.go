@@ -0,0 +1,240 @@
+package emaildomainstats
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// DomainValidator is consulted for every extracted domain before it is recorded in a
+// DomainStatTracker. Implementations should be safe for concurrent use; Validate is called from
+// every worker goroutine.
+type DomainValidator interface {
+	Validate(ctx context.Context, domain string) error
+}
+
+// NoopValidator is a DomainValidator that accepts every domain. It is the default used when no
+// validator is configured via WithDomainValidator.
+type NoopValidator struct{}
+
+// Validate always returns nil.
+func (NoopValidator) Validate(ctx context.Context, domain string) error {
+	return nil
+}
+
+// staticAllowlistValidator rejects any domain not present in a fixed set.
+type staticAllowlistValidator struct {
+	set map[string]bool
+}
+
+// StaticAllowlistValidator returns a DomainValidator that only accepts domains present in set.
+func StaticAllowlistValidator(set map[string]bool) DomainValidator {
+	return &staticAllowlistValidator{set: set}
+}
+
+// Validate returns an error if domain is not a key in the allowlist.
+func (v *staticAllowlistValidator) Validate(ctx context.Context, domain string) error {
+	if !v.set[domain] {
+		return fmt.Errorf("domain %q is not in the allowlist", domain)
+	}
+	return nil
+}
+
+// DomainValidationMode selects the DNS lookup strategy an MXValidator uses, or (as NoValidation)
+// whether WithDomainValidation is enabled at all.
+type DomainValidationMode int
+
+const (
+	// NoValidation performs no DNS validation. It is the zero value, so a Processor that never
+	// calls WithDomainValidation defaults to it.
+	NoValidation DomainValidationMode = iota
+	// MXLookup accepts a domain if it has an MX record, falling back to A/AAAA per RFC 5321 §5.1.
+	// It is MXValidator's own default regardless of NoValidation, since a directly-constructed
+	// MXValidator is never asked to skip validation.
+	MXLookup
+	// ALookup accepts a domain if it resolves via A/AAAA, without considering MX records.
+	ALookup
+)
+
+// mxCacheEntry memoizes the outcome of a single lookup, its expiry, and its place in the LRU list
+// used to bound cache size.
+type mxCacheEntry struct {
+	domain    string
+	err       error
+	expiresAt time.Time
+}
+
+// MXValidator validates domains by performing an MX lookup, falling back to A/AAAA when no MX
+// record exists (RFC 5321 §5.1), or - constructed with WithMXLookupMode(ALookup) - a bare A/AAAA
+// lookup. Lookup outcomes, positive and negative, are memoized so that a CSV with heavy domain skew
+// doesn't issue a DNS query per row.
+type MXValidator struct {
+	resolver *net.Resolver
+	mode     DomainValidationMode
+	cacheTTL time.Duration
+	timeout  time.Duration
+	sem      chan struct{} // nil when WithMXConcurrency was not set
+
+	mu       sync.Mutex
+	cacheCap int // 0 means unbounded
+	ll       *list.List
+	cache    map[string]*list.Element
+}
+
+// MXValidatorOption configures an MXValidator returned by NewMXValidator.
+type MXValidatorOption func(*MXValidator)
+
+// WithMXLookupMode overrides the DNS record type required to accept a domain. The default is
+// MXLookup.
+func WithMXLookupMode(mode DomainValidationMode) MXValidatorOption {
+	return func(v *MXValidator) { v.mode = mode }
+}
+
+// WithMXTimeout bounds each individual DNS lookup. The default is no per-lookup timeout beyond
+// whatever deadline the Validate caller's ctx already carries.
+func WithMXTimeout(d time.Duration) MXValidatorOption {
+	return func(v *MXValidator) { v.timeout = d }
+}
+
+// WithMXCacheSize bounds the number of domains memoized, evicting the least recently used entry
+// once full. The default is unbounded. Customer CSVs typically skew heavily toward a handful of
+// large providers, so a modest size is usually enough to make repeated domains free even against a
+// million-row file.
+func WithMXCacheSize(n int) MXValidatorOption {
+	return func(v *MXValidator) { v.cacheCap = n }
+}
+
+// WithMXConcurrency bounds the number of DNS lookups this validator allows in flight at once,
+// independent of the Processor's own WithConcurrency. This matters because a DNS lookup is
+// typically far slower than parsing a row, so validating with the same concurrency as the rest of
+// the pipeline can otherwise open far more simultaneous queries to the resolver than intended. The
+// default is unbounded.
+func WithMXConcurrency(n int) MXValidatorOption {
+	return func(v *MXValidator) {
+		v.sem = make(chan struct{}, n)
+	}
+}
+
+// NewMXValidator returns an MXValidator that uses resolver (net.DefaultResolver if nil) for
+// lookups and caches results for cacheTTL (0 means entries never expire by age; combine with
+// WithMXCacheSize to still bound memory).
+func NewMXValidator(resolver *net.Resolver, cacheTTL time.Duration, opts ...MXValidatorOption) *MXValidator {
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	v := &MXValidator{
+		resolver: resolver,
+		cacheTTL: cacheTTL,
+		ll:       list.New(),
+		cache:    make(map[string]*list.Element),
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+// Validate resolves domain per v's configured mode, falling back to A/AAAA for the MXLookup
+// default, and returns an error if neither exists. Results are cached per v's configured TTL and
+// size bound.
+func (v *MXValidator) Validate(ctx context.Context, domain string) error {
+	if err, ok := v.load(domain); ok {
+		return err
+	}
+
+	if v.sem != nil {
+		select {
+		case v.sem <- struct{}{}:
+			defer func() { <-v.sem }()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	lookupCtx := ctx
+	if v.timeout > 0 {
+		var cancel context.CancelFunc
+		lookupCtx, cancel = context.WithTimeout(ctx, v.timeout)
+		defer cancel()
+	}
+
+	err := v.lookup(lookupCtx, domain)
+	v.store(domain, err)
+	return err
+}
+
+// lookup performs the actual DNS resolution, uncached.
+func (v *MXValidator) lookup(ctx context.Context, domain string) error {
+	if v.mode == ALookup {
+		if _, err := v.resolver.LookupHost(ctx, domain); err != nil {
+			return fmt.Errorf("domain %q has no A/AAAA records: %w", domain, err)
+		}
+		return nil
+	}
+
+	if _, err := v.resolver.LookupMX(ctx, domain); err == nil {
+		return nil
+	}
+
+	// RFC 5321 §5.1: if no MX record exists, an A/AAAA record is used as the fallback mail
+	// exchange.
+	if _, err := v.resolver.LookupHost(ctx, domain); err != nil {
+		return fmt.Errorf("domain %q has no MX or A/AAAA records: %w", domain, err)
+	}
+
+	return nil
+}
+
+// load returns the cached outcome for domain, if present and not expired, marking it as most
+// recently used.
+func (v *MXValidator) load(domain string) (error, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	el, ok := v.cache[domain]
+	if !ok {
+		return nil, false
+	}
+
+	entry := el.Value.(*mxCacheEntry)
+	if v.cacheTTL > 0 && time.Now().After(entry.expiresAt) {
+		v.ll.Remove(el)
+		delete(v.cache, domain)
+		return nil, false
+	}
+
+	v.ll.MoveToFront(el)
+	return entry.err, true
+}
+
+// store records the outcome of validating domain, evicting the least recently used entry if the
+// cache has a configured capacity and is full.
+func (v *MXValidator) store(domain string, err error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	var expiresAt time.Time
+	if v.cacheTTL > 0 {
+		expiresAt = time.Now().Add(v.cacheTTL)
+	}
+
+	if el, ok := v.cache[domain]; ok {
+		entry := el.Value.(*mxCacheEntry)
+		entry.err = err
+		entry.expiresAt = expiresAt
+		v.ll.MoveToFront(el)
+		return
+	}
+
+	el := v.ll.PushFront(&mxCacheEntry{domain: domain, err: err, expiresAt: expiresAt})
+	v.cache[domain] = el
+
+	if v.cacheCap > 0 && v.ll.Len() > v.cacheCap {
+		oldest := v.ll.Back()
+		v.ll.Remove(oldest)
+		delete(v.cache, oldest.Value.(*mxCacheEntry).domain)
+	}
+}
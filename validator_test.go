@@ -0,0 +1,29 @@
+package emaildomainstats_test
+
+import (
+	"context"
+	"testing"
+
+	emaildomainstats "github.com/fcuenca/go-tech-test"
+)
+
+func TestNoopValidator(t *testing.T) {
+	v := emaildomainstats.NoopValidator{}
+	if err := v.Validate(context.Background(), "anything.com"); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+func TestStaticAllowlistValidator(t *testing.T) {
+	v := emaildomainstats.StaticAllowlistValidator(map[string]bool{
+		"example.com": true,
+	})
+
+	if err := v.Validate(context.Background(), "example.com"); err != nil {
+		t.Errorf("expected example.com to be allowed, got %v", err)
+	}
+
+	if err := v.Validate(context.Background(), "exmaple.com"); err == nil {
+		t.Error("expected typo domain to be rejected, got nil error")
+	}
+}